@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"sync"
+)
+
+/**
+Returned by AckingJsonWriter when replay buffer is full, Sync makes room.
+ */
+var ErrReplayBufferFull = errors.New("fs: replay buffer is full")
+
+/**
+Default number of records kept in replay buffer.
+ */
+const DefaultReplayLimit = 10000
+
+/**
+SeqNo is the 1-based sequence number of the record written by AckingJsonWriter, zero means no record.
+ */
+type SeqNo uint64
+
+/**
+AckingJsonWriter assigns sequence numbers to records and tracks the durable watermark, safe for concurrent use.
+Records written after the last successful Sync are kept in the bounded replay buffer, so after write failure they could be replayed
+in to a new writer without loss or duplication. Callers ack upstream only up to DurableSeqNo.
+ */
+type AckingJsonWriter struct {
+	mu      sync.Mutex
+	w       JsonWriter
+	limit   int
+	next    SeqNo
+	durable SeqNo
+	replay  []json.RawMessage
+	closed  bool
+}
+
+/**
+Creates acking writer over the writer, replayLimit is the maximum number of records between Sync calls, non-positive means DefaultReplayLimit.
+ */
+func NewAckingJsonWriter(w JsonWriter, replayLimit int) *AckingJsonWriter {
+	if replayLimit <= 0 {
+		replayLimit = DefaultReplayLimit
+	}
+	return &AckingJsonWriter{w: w, limit: replayLimit, next: 1}
+}
+
+/*
+Writes already formatted JSON message, returns its sequence number.
+Failed write takes no sequence number and is not kept for replay, so the record could be retried after Replay without duplication.
+ */
+func (t *AckingJsonWriter) WriteRaw(message json.RawMessage) (SeqNo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return 0, ErrClosed
+	}
+	if len(t.replay) >= t.limit {
+		return 0, ErrReplayBufferFull
+	}
+	record := append(json.RawMessage(nil), message...)
+	if err := t.w.WriteRaw(record); err != nil {
+		return 0, err
+	}
+	t.replay = append(t.replay, record)
+	seq := t.next
+	t.next++
+	return seq, nil
+}
+
+/*
+Writes golang object, proto.Message is marshaled with MarshalOptions of the writer.
+ */
+func (t *AckingJsonWriter) Write(object interface{}) (SeqNo, error) {
+	var data []byte
+	var err error
+	if m, ok := object.(proto.Message); ok {
+		data, err = t.w.Options().MarshalOptions.Marshal(m)
+	} else {
+		data, err = json.Marshal(object)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return t.WriteRaw(data)
+}
+
+/*
+Syncs the writer and advances durable watermark to the last written record on success.
+ */
+func (t *AckingJsonWriter) Sync() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrClosed
+	}
+	if err := t.w.Sync(); err != nil {
+		return err
+	}
+	t.commit()
+	return nil
+}
+
+func (t *AckingJsonWriter) commit() {
+	t.durable = t.next - 1
+	t.replay = t.replay[:0]
+}
+
+/*
+Gets sequence number of the last record synced to disk.
+ */
+func (t *AckingJsonWriter) DurableSeqNo() SeqNo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.durable
+}
+
+/*
+Gets records written after the last successful Sync in write order.
+ */
+func (t *AckingJsonWriter) InFlight() []json.RawMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]json.RawMessage(nil), t.replay...)
+}
+
+/*
+Switches to the new writer after failure of the current one, e.g. reopened part or retrying writer, and writes in-flight records there.
+Current writer is not closed, its content after the durable watermark must be discarded by the caller.
+ */
+func (t *AckingJsonWriter) Replay(w JsonWriter) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrClosed
+	}
+	t.w = w
+	for _, record := range t.replay {
+		if err := w.WriteRaw(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Syncs and closes the writer, successful Close makes all records durable.
+Writes, Sync, Replay and repeated Close after Close return ErrClosed, even if Close failed.
+ */
+func (t *AckingJsonWriter) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrClosed
+	}
+	t.closed = true
+	if err := t.w.Sync(); err != nil {
+		t.w.Close()
+		return err
+	}
+	if err := t.w.Close(); err != nil {
+		return err
+	}
+	t.commit()
+	return nil
+}
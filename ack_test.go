@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAckingJsonWriterFailedWriteRetry(t *testing.T) {
+	failing := &memJsonWriter{fail: 2}
+	w := NewAckingJsonWriter(failing, 10)
+	if seq, err := w.WriteRaw([]byte(`{"n":1}`)); err != nil || seq != 1 {
+		t.Fatalf("seq %d, err %v", seq, err)
+	}
+	if seq, err := w.WriteRaw([]byte(`{"n":2}`)); err != errFakeWrite || seq != 0 {
+		t.Fatalf("failed write returned seq %d, err %v", seq, err)
+	}
+	if n := len(w.InFlight()); n != 1 {
+		t.Fatalf("failed record kept for replay, %d in flight", n)
+	}
+	next := &memJsonWriter{}
+	if err := w.Replay(next); err != nil {
+		t.Fatal(err)
+	}
+	if seq, err := w.WriteRaw([]byte(`{"n":2}`)); err != nil || seq != 2 {
+		t.Fatalf("retry seq %d, err %v", seq, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(next.records) != 2 || string(next.records[1]) != `{"n":2}` || w.DurableSeqNo() != 2 {
+		t.Fatalf("records %q, durable %d", next.records, w.DurableSeqNo())
+	}
+}
+
+func TestAckingJsonWriterReplayLimit(t *testing.T) {
+	w := NewAckingJsonWriter(&memJsonWriter{}, 2)
+	w.WriteRaw([]byte(`1`))
+	w.Write(2)
+	if _, err := w.WriteRaw([]byte(`3`)); err != ErrReplayBufferFull {
+		t.Fatalf("want ErrReplayBufferFull, got %v", err)
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if w.DurableSeqNo() != 2 || len(w.InFlight()) != 0 {
+		t.Fatalf("durable %d, in flight %d", w.DurableSeqNo(), len(w.InFlight()))
+	}
+	if seq, err := w.WriteRaw([]byte(`3`)); err != nil || seq != 3 {
+		t.Fatalf("seq %d, err %v", seq, err)
+	}
+}
+
+/*
+Reads seq numbers of NDJSON records of the file in order.
+ */
+func readAckedSeqs(t *testing.T, path string) []SeqNo {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var seqs []SeqNo
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record struct{ Seq SeqNo }
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("corrupt record %q: %v", scanner.Text(), err)
+		}
+		seqs = append(seqs, record.Seq)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return seqs
+}
+
+func checkSeqs(t *testing.T, name string, got []SeqNo, want SeqNo) {
+	t.Helper()
+	if len(got) != int(want) {
+		t.Fatalf("%s: %d records on disk, want %d", name, len(got), want)
+	}
+	for i, seq := range got {
+		if seq != SeqNo(i+1) {
+			t.Fatalf("%s: record %d has seq %d", name, i, seq)
+		}
+	}
+}
+
+func TestAckingJsonWriterCrashConsistency(t *testing.T) {
+	const records, syncEvery = 40, 5
+	for failWrite := 0; failWrite <= 8; failWrite++ {
+		for failSync := 0; failSync <= 3; failSync++ {
+			name := fmt.Sprintf("write%d-sync%d", failWrite, failSync)
+			path := filepath.Join(t.TempDir(), "acked.json")
+			file, err := openFaultFile(path, failWrite, failSync)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := NewAckingJsonWriter(newFileJsonWriter(file), syncEvery)
+			crashed := false
+			for n := SeqNo(1); n <= records; {
+				durable := w.DurableSeqNo()
+				seq, err := w.WriteRaw([]byte(fmt.Sprintf(`{"seq":%d}`, n)))
+				if err == nil && seq != n {
+					t.Fatalf("%s: record %d got seq %d", name, n, seq)
+				}
+				synced := false
+				if err == nil && n%syncEvery == 0 {
+					err, synced = w.Sync(), true
+				}
+				if err == nil {
+					n++
+					continue
+				}
+				if crashed {
+					t.Fatalf("%s: second failure %v", name, err)
+				}
+				crashed = true
+				if w.DurableSeqNo() != durable {
+					t.Fatalf("%s: durable moved from %d to %d on failure", name, durable, w.DurableSeqNo())
+				}
+				if err := file.crash(); err != nil {
+					t.Fatal(err)
+				}
+				checkSeqs(t, name+" after crash", readAckedSeqs(t, path), w.DurableSeqNo())
+				if file, err = openFaultFile(path, 0, 0); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.Replay(newFileJsonWriter(file)); err != nil {
+					t.Fatal(err)
+				}
+				if synced {
+					// the record was written before failed Sync, it is replayed and synced, not written again
+					if err := w.Sync(); err != nil {
+						t.Fatal(err)
+					}
+					n++
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if wantCrash := failWrite > 0 || failSync > 0; crashed != wantCrash {
+				t.Fatalf("%s: crashed %v", name, crashed)
+			}
+			if w.DurableSeqNo() != records {
+				t.Fatalf("%s: durable %d after Close", name, w.DurableSeqNo())
+			}
+			checkSeqs(t, name, readAckedSeqs(t, path), records)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+)
+
+type recordBytesReader struct {
+	next   func() ([]byte, error)
+	close  func() error
+	buf    []byte
+	err    error
+	closed bool
+}
+
+func (t *recordBytesReader) Read(p []byte) (int, error) {
+	if t.closed {
+		return 0, ErrClosed
+	}
+	for len(t.buf) == 0 {
+		if t.err != nil {
+			return 0, t.err
+		}
+		record, err := t.next()
+		if err != nil {
+			t.err = err
+			continue
+		}
+		t.buf = append(append(t.buf[:0], record...), '\n')
+	}
+	n := copy(p, t.buf)
+	t.buf = t.buf[n:]
+	return n, nil
+}
+
+func (t *recordBytesReader) Close() error {
+	if t.closed {
+		return ErrClosed
+	}
+	t.closed = true
+	t.buf = nil
+	if t.close == nil {
+		return nil
+	}
+	return t.close()
+}
+
+/**
+Exposes JSON reader as NDJSON bytes, each raw record is followed by new line. Close closes the reader.
+Reads and repeated Close after Close return ErrClosed, the same for the other adapters.
+ */
+func JsonBytesReader(r JsonReader) io.ReadCloser {
+	return &recordBytesReader{
+		next: func() ([]byte, error) {
+			return r.ReadRaw()
+		},
+		close: r.Close,
+	}
+}
+
+/**
+Exposes proto reader as NDJSON bytes, each message is read in to holder and marshaled with options. Close closes the reader.
+ */
+func ProtoAsJsonReader(r ProtoReader, holder proto.Message, mo protojson.MarshalOptions) io.ReadCloser {
+	return &recordBytesReader{
+		next: func() ([]byte, error) {
+			if err := r.ReadTo(holder); err != nil {
+				return nil, err
+			}
+			return mo.Marshal(holder)
+		},
+		close: r.Close,
+	}
+}
+
+/**
+Exposes CSV reader as NDJSON bytes, header is read first and each record is a JSON object with keys in header order.
+Close closes the reader, header error closes the reader and is returned.
+ */
+func CsvAsJsonReader(r CsvReader) (io.ReadCloser, error) {
+	f, err := r.ReadHeader()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	header := f.Header()
+	return &recordBytesReader{
+		next: func() ([]byte, error) {
+			record, err := f.Next()
+			if err != nil {
+				return nil, err
+			}
+			return csvRecordJson(header, record.Record())
+		},
+		close: r.Close,
+	}, nil
+}
+
+func csvRecordJson(header, values []string) ([]byte, error) {
+	out := []byte{'{'}
+	for i, name := range header {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		var value string
+		if i < len(values) {
+			value = values[i]
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		out = append(append(append(out, key...), ':'), data...)
+	}
+	return append(out, '}'), nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"io"
+	"testing"
+)
+
+func TestCsvAsJsonReader(t *testing.T) {
+	r := &memCsvReader{rows: [][]string{{"id", "name"}, {"1", "a\"b"}, {"2"}}}
+	rc, err := CsvAsJsonReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"id\":\"1\",\"name\":\"a\\\"b\"}\n{\"id\":\"2\",\"name\":\"\"}\n"; string(data) != want {
+		t.Fatalf("got %q, want %q", data, want)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if r.closed != 1 {
+		t.Fatalf("reader closed %d times", r.closed)
+	}
+}
+
+func TestCsvAsJsonReaderHeaderError(t *testing.T) {
+	r := &memCsvReader{}
+	if _, err := CsvAsJsonReader(r); err != io.EOF {
+		t.Fatalf("want io.EOF, got %v", err)
+	}
+	if r.closed != 1 {
+		t.Fatal("reader not closed on header error")
+	}
+}
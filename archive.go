@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+)
+
+/**
+Returned when archive entry name is absolute or escapes the archive root.
+ */
+var ErrUnsafeEntryName = errors.New("fs: unsafe archive entry name")
+
+/**
+Cleans archive entry name and rejects path traversal even though entries are never extracted to disk.
+ */
+func SanitizeEntryName(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, ":") {
+		return "", ErrUnsafeEntryName
+	}
+	clean := path.Clean(name)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", ErrUnsafeEntryName
+	}
+	return clean, nil
+}
+
+/**
+Name of the manifest entry written last in to the tar bundle of split parts.
+ */
+const ManifestEntryName = "manifest.json"
+
+/**
+Part description in the manifest.
+ */
+type ManifestPart struct {
+	Name    string `json:"name"`
+	Records int64  `json:"records"`
+	Bytes   int64  `json:"bytes"`
+	SHA256  string `json:"sha256"`
+}
+
+/**
+Manifest of split parts.
+ */
+type Manifest struct {
+	Parts   []ManifestPart `json:"parts"`
+	Records int64          `json:"records"`
+}
+
+/**
+Single entry of the archive.
+ */
+type ArchiveEntry struct {
+	Name           string
+	Size           int64
+	CompressedSize int64
+	ModTime        time.Time
+}
+
+/**
+Base interface of opened archive, entries are read through the regular readers. Nested `.gz` entries are decompressed.
+ */
+type Archive interface {
+
+	/*
+	Gets sanitized entries of the archive, Zip64 entries larger than 4 GB are supported.
+	 */
+	Entries() []ArchiveEntry
+
+	/*
+	Opens JSON entry.
+	 */
+	OpenJson(name string) (JsonReader, error)
+
+	/*
+	Opens protofile entry.
+	 */
+	OpenProto(name string) (ProtoReader, error)
+
+	/*
+	Opens CSV entry.
+	 */
+	OpenCsv(name string, valueProcessors ...CsvValueProcessor) (CsvReader, error)
+
+	/*
+	Closes archive file.
+	 */
+	Close() error
+
+}
+
+/**
+Base interface for archives with data files.
+ */
+type ArchiveService interface {
+
+	/*
+	Opens `.zip` archive from local file system.
+	 */
+	OpenZipArchive(filePath string) (Archive, error)
+
+	/*
+	Joins archive entries matching glob pattern in to one file using the join semantics of the output format, e.g. CSV header written once.
+	 */
+	JoinFromZip(outputFilePath string, zipPath string, pattern string) error
+
+	/*
+	Joins parts stored in tar bundle in the order of entries, manifest entry is skipped.
+	 */
+	JoinFromTar(outputFilePath string, tarPath string) error
+
+	/*
+	Opens single JSON entry of tar bundle.
+	 */
+	OpenTarEntryJson(tarPath, entryName string) (JsonReader, error)
+
+}
@@ -0,0 +1,30 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestSanitizeEntryName(t *testing.T) {
+	for name, want := range map[string]string{
+		"part-0001.json":    "part-0001.json",
+		"dir/./part.json":   "dir/part.json",
+		"dir\\part.json":    "dir/part.json",
+		"a/../b/part.json":  "b/part.json",
+		"dir//part.json.gz": "dir/part.json.gz",
+	} {
+		got, err := SanitizeEntryName(name)
+		if err != nil || got != want {
+			t.Errorf("SanitizeEntryName(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"", ".", "a/..", "..", "../x", "a/../../x", "/etc/passwd", "\\\\host\\share", "C:\\x", "c:x"} {
+		if _, err := SanitizeEntryName(name); err != ErrUnsafeEntryName {
+			t.Errorf("SanitizeEntryName(%q) = %v, want ErrUnsafeEntryName", name, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/sprintframework/fs"
+	"github.com/sprintframework/fs/fixtures"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func BenchmarkBloomSetZipf(b *testing.B) {
+	keys := fixtures.ZipfKeys(rand.New(rand.NewSource(1)), 1.1, 1<<20)
+	values := make([]string, 1<<16)
+	for i := range values {
+		values[i] = strconv.FormatUint(keys(), 10)
+	}
+	s, err := fs.NewBloomSet(len(values), 0.01)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := values[i%len(values)]
+		s.Add(v)
+		s.Contains(v)
+	}
+}
+
+func BenchmarkProtoFrames(b *testing.B) {
+	strings := fixtures.RandomStrings(rand.New(rand.NewSource(1)), 64)
+	h := fs.ProtoHeader{Version: fs.ProtoHeaderVersion, Checksums: true, FrameFlags: true}
+	var body []byte
+	records := 4096
+	for i := 0; i < records; i++ {
+		payload, err := proto.Marshal(wrapperspb.String(strings()))
+		if err != nil {
+			b.Fatal(err)
+		}
+		body = fs.AppendProtoFrame(body, fs.LengthFraming, h, payload, 0)
+	}
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		frames := fs.NewProtoFrameReader(bufio.NewReader(bytes.NewReader(body)), fs.LengthFraming, h, 0, 0)
+		for {
+			if _, _, err := frames.Next(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkProtoBatchWrite(b *testing.B) {
+	strings := fixtures.RandomStrings(rand.New(rand.NewSource(1)), 64)
+	h := fs.ProtoHeader{Version: fs.ProtoHeaderVersion, Checksums: true}
+	messages := make([]proto.Message, 1024)
+	for i := range messages {
+		messages[i] = wrapperspb.String(strings())
+	}
+	b.Run("PerRecord", func(b *testing.B) {
+		w := bufio.NewWriter(io.Discard)
+		for i := 0; i < b.N; i++ {
+			for _, message := range messages {
+				payload, err := proto.Marshal(message)
+				if err != nil {
+					b.Fatal(err)
+				}
+				w.Write(fs.AppendProtoFrame(nil, fs.LengthFraming, h, payload, 0))
+				w.Flush()
+			}
+		}
+		b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(messages)), "ns/record")
+	})
+	b.Run("Batch", func(b *testing.B) {
+		w := bufio.NewWriter(io.Discard)
+		var buf []byte
+		for i := 0; i < b.N; i++ {
+			var err error
+			buf, err = fs.AppendProtoBatch(buf[:0], fs.LengthFraming, h, messages, proto.MarshalOptions{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			w.Write(buf)
+			w.Flush()
+		}
+		b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(messages)), "ns/record")
+	})
+}
+
+func BenchmarkDecodeWireMessage(b *testing.B) {
+	strings := fixtures.RandomStrings(rand.New(rand.NewSource(1)), 32)
+	payloads := make([][]byte, 1024)
+	for i := range payloads {
+		payload, err := proto.Marshal(wrapperspb.String(strings()))
+		if err != nil {
+			b.Fatal(err)
+		}
+		payloads[i] = payload
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fs.DecodeWireMessage(payloads[i%len(payloads)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSortSpecThreeKeys(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	partners := fixtures.ZipfKeys(r, 1.2, 1000)
+	times := fixtures.Timestamps(r, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	records := make([]json.RawMessage, 1<<12)
+	for i := range records {
+		records[i], _ = json.Marshal(map[string]interface{}{
+			"partner_id": "p" + strconv.FormatUint(partners(), 10),
+			"event_time": times().Format(time.RFC3339),
+			"id":         r.Int63(),
+		})
+	}
+	cmp := fs.SortSpec{
+		{Field: "partner_id"},
+		{Field: "event_time", Type: fs.TimeKey, Descending: true},
+		{Field: "id", Type: fs.NumericKey},
+	}.JsonComparator()
+	chunk := make([]json.RawMessage, len(records))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(chunk, records)
+		sort.SliceStable(chunk, func(i, j int) bool {
+			c, _ := cmp(chunk[i], chunk[j])
+			return c < 0
+		})
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(records)), "ns/record")
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"container/list"
+	"os"
+	"sync"
+	"time"
+)
+
+/**
+ReadCache keeps fully-decompressed content of small files keyed by path, mtime and size, it backs EnableReadCache of the service.
+Files larger than 1/16 of maxBytes are not cached, entries are evicted in LRU order by total bytes and after ttl.
+Cached content is shared by all readers and must not be modified. Safe for concurrent use.
+ */
+type ReadCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	ttl      time.Duration
+	clock    Clock
+	hook     MetricsHook
+	lru      *list.List
+	entries  map[string]*list.Element
+	bytes    int64
+}
+
+type cacheEntry struct {
+	path    string
+	modTime time.Time
+	size    int64
+	content []byte
+	expires time.Time
+}
+
+/**
+Creates read cache, ttl is not limited if it is not positive, clock and hook could be nil.
+ */
+func NewReadCache(maxBytes int64, ttl time.Duration, clock Clock, hook MetricsHook) *ReadCache {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &ReadCache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		clock:    clock,
+		hook:     hook,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+/*
+Gets cached content of the file if its mtime and size match info, stale entry is removed.
+ */
+func (t *ReadCache) Get(filePath string, info os.FileInfo) ([]byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	el, ok := t.entries[filePath]
+	if ok {
+		e := el.Value.(*cacheEntry)
+		if e.size == info.Size() && e.modTime.Equal(info.ModTime()) && (t.ttl <= 0 || t.clock.Now().Before(e.expires)) {
+			t.lru.MoveToFront(el)
+			t.inc(MetricReadCacheHit)
+			return e.content, true
+		}
+		t.remove(el)
+	}
+	t.inc(MetricReadCacheMiss)
+	return nil, false
+}
+
+/*
+Caches content of the file read with info, content larger than the per-file cap is ignored.
+ */
+func (t *ReadCache) Put(filePath string, info os.FileInfo, content []byte) {
+	size := int64(len(content))
+	if size > t.maxBytes/16 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if el, ok := t.entries[filePath]; ok {
+		t.remove(el)
+	}
+	e := &cacheEntry{path: filePath, modTime: info.ModTime(), size: info.Size(), content: content, expires: t.clock.Now().Add(t.ttl)}
+	t.entries[filePath] = t.lru.PushFront(e)
+	t.bytes += size
+	for t.bytes > t.maxBytes {
+		t.remove(t.lru.Back())
+		t.inc(MetricReadCacheEviction)
+	}
+	t.gauge()
+}
+
+/*
+Removes cached content of the file if any, writers call it for the path they write.
+ */
+func (t *ReadCache) Invalidate(filePath string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if el, ok := t.entries[filePath]; ok {
+		t.remove(el)
+		t.gauge()
+	}
+}
+
+/*
+Removes all cached content.
+ */
+func (t *ReadCache) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lru.Init()
+	t.entries = make(map[string]*list.Element)
+	t.bytes = 0
+	t.gauge()
+}
+
+/*
+Gets total bytes of cached content.
+ */
+func (t *ReadCache) Bytes() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.bytes
+}
+
+func (t *ReadCache) remove(el *list.Element) {
+	e := t.lru.Remove(el).(*cacheEntry)
+	delete(t.entries, e.path)
+	t.bytes -= int64(len(e.content))
+}
+
+func (t *ReadCache) inc(name string) {
+	if t.hook != nil {
+		t.hook.IncCounter(name, 1)
+	}
+}
+
+func (t *ReadCache) gauge() {
+	if t.hook != nil {
+		t.hook.SetGauge(MetricReadCacheBytes, t.bytes)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubFileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func (i stubFileInfo) Name() string       { return "stub" }
+func (i stubFileInfo) Size() int64        { return i.size }
+func (i stubFileInfo) Mode() os.FileMode  { return 0644 }
+func (i stubFileInfo) ModTime() time.Time { return i.modTime }
+func (i stubFileInfo) IsDir() bool        { return false }
+func (i stubFileInfo) Sys() interface{}   { return nil }
+
+var cacheEpoch = time.Unix(1700000000, 0)
+
+func TestReadCacheHitMiss(t *testing.T) {
+	metrics := &memMetrics{}
+	c := NewReadCache(1600, 0, nil, metrics)
+	info := stubFileInfo{size: 3, modTime: cacheEpoch}
+	if _, ok := c.Get("a", info); ok {
+		t.Fatal("hit on empty cache")
+	}
+	c.Put("a", info, []byte("abc"))
+	if content, ok := c.Get("a", info); !ok || string(content) != "abc" {
+		t.Fatalf("want hit, got %q %v", content, ok)
+	}
+	if metrics.counter(MetricReadCacheHit) != 1 || metrics.counter(MetricReadCacheMiss) != 1 {
+		t.Fatalf("counters %v", metrics.counters)
+	}
+}
+
+func TestReadCacheInvalidation(t *testing.T) {
+	c := NewReadCache(1600, 0, nil, nil)
+	info := stubFileInfo{size: 3, modTime: cacheEpoch}
+	c.Put("a", info, []byte("abc"))
+	if _, ok := c.Get("a", stubFileInfo{size: 3, modTime: cacheEpoch.Add(time.Second)}); ok {
+		t.Fatal("hit after mtime change")
+	}
+	if _, ok := c.Get("a", info); ok {
+		t.Fatal("stale entry was not removed")
+	}
+	c.Put("a", info, []byte("abc"))
+	if _, ok := c.Get("a", stubFileInfo{size: 4, modTime: cacheEpoch}); ok {
+		t.Fatal("hit after size change")
+	}
+	c.Put("a", info, []byte("abc"))
+	c.Invalidate("a")
+	if _, ok := c.Get("a", info); ok || c.Bytes() != 0 {
+		t.Fatalf("hit after invalidate, %d bytes", c.Bytes())
+	}
+}
+
+func TestReadCacheTtl(t *testing.T) {
+	clock := NewFakeClock(cacheEpoch)
+	c := NewReadCache(1600, time.Minute, clock, nil)
+	info := stubFileInfo{size: 1, modTime: cacheEpoch}
+	c.Put("a", info, []byte("x"))
+	clock.Advance(59 * time.Second)
+	if _, ok := c.Get("a", info); !ok {
+		t.Fatal("expired before ttl")
+	}
+	clock.Advance(time.Second)
+	if _, ok := c.Get("a", info); ok {
+		t.Fatal("hit after ttl")
+	}
+}
+
+func TestReadCacheEviction(t *testing.T) {
+	metrics := &memMetrics{}
+	c := NewReadCache(160, 0, nil, metrics)
+	content := bytes.Repeat([]byte("x"), 10)
+	info := stubFileInfo{size: 10, modTime: cacheEpoch}
+	for i := 0; i < 16; i++ {
+		c.Put(strconv.Itoa(i), info, content)
+	}
+	c.Get("0", info)
+	c.Put("16", info, content)
+	if _, ok := c.Get("1", info); ok {
+		t.Fatal("least recently used entry was not evicted")
+	}
+	if _, ok := c.Get("0", info); !ok {
+		t.Fatal("recently used entry was evicted")
+	}
+	if c.Bytes() != 160 || metrics.counter(MetricReadCacheEviction) != 1 {
+		t.Fatalf("%d bytes, %d evictions", c.Bytes(), metrics.counter(MetricReadCacheEviction))
+	}
+	c.Put("big", stubFileInfo{size: 11, modTime: cacheEpoch}, bytes.Repeat([]byte("x"), 11))
+	if _, ok := c.Get("big", stubFileInfo{size: 11, modTime: cacheEpoch}); ok {
+		t.Fatal("file over per-file cap was cached")
+	}
+}
+
+func TestReadCacheReadersDuringInvalidation(t *testing.T) {
+	c := NewReadCache(1<<20, 0, nil, &memMetrics{})
+	version := func(i int) (os.FileInfo, []byte) {
+		content := bytes.Repeat([]byte{byte('a' + i%26)}, 100+i%7)
+		return stubFileInfo{size: int64(len(content)), modTime: cacheEpoch.Add(time.Duration(i))}, content
+	}
+	var mu sync.RWMutex
+	current := 0
+	info, content := version(current)
+	c.Put("ref.json", info, content)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				mu.RLock()
+				info, want := version(current)
+				mu.RUnlock()
+				content, ok := c.Get("ref.json", info)
+				if !ok {
+					c.Put("ref.json", info, want)
+					continue
+				}
+				got, err := io.ReadAll(bytes.NewReader(content))
+				if err != nil || !bytes.Equal(got, want) {
+					t.Errorf("served %q for version of %d bytes", got, info.Size())
+					return
+				}
+			}
+		}()
+	}
+	for i := 1; i < 500; i++ {
+		mu.Lock()
+		current = i
+		mu.Unlock()
+		c.Invalidate("ref.json")
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "sort"
+
+/**
+Capability is a feature of the implementation that could be detected at runtime.
+ */
+type Capability string
+
+/**
+Codec capabilities.
+ */
+const (
+	CapabilityGzip Capability = "codec.gzip"
+	CapabilityZstd Capability = "codec.zstd"
+)
+
+/**
+Proto framing capabilities.
+ */
+const (
+	CapabilityLengthFraming Capability = "framing.length"
+	CapabilityFrameFlags    Capability = "framing.flags"
+	CapabilityVarintFraming Capability = "framing.varint"
+	CapabilityChecksums     Capability = "framing.checksums"
+)
+
+/**
+Feature capabilities.
+ */
+const (
+	CapabilityAtomicWrites  Capability = "feature.atomic_writes"
+	CapabilityAppend        Capability = "feature.append"
+	CapabilityManifest      Capability = "feature.manifest"
+	CapabilityDictionary    Capability = "feature.dictionary"
+	CapabilityTransactions  Capability = "feature.transactions"
+	CapabilityReadCache     Capability = "feature.read_cache"
+	CapabilityFieldMask     Capability = "feature.field_mask"
+	CapabilityEncryption    Capability = "feature.encryption"
+	CapabilityFollowMode    Capability = "feature.follow"
+)
+
+/**
+CapabilitySet is the set of capabilities, nil set is empty.
+ */
+type CapabilitySet map[Capability]struct{}
+
+/**
+Creates set of capabilities.
+ */
+func NewCapabilitySet(list ...Capability) CapabilitySet {
+	set := make(CapabilitySet, len(list))
+	for _, c := range list {
+		set[c] = struct{}{}
+	}
+	return set
+}
+
+/*
+Checks if capability is in the set.
+ */
+func (s CapabilitySet) Has(c Capability) bool {
+	_, ok := s[c]
+	return ok
+}
+
+/*
+Gets sorted list of capabilities.
+ */
+func (s CapabilitySet) List() []Capability {
+	list := make([]Capability, 0, len(s))
+	for c := range s {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
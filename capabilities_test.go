@@ -0,0 +1,26 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapabilitySet(t *testing.T) {
+	s := NewCapabilitySet(CapabilityZstd, CapabilityGzip, CapabilityAppend, CapabilityGzip)
+	if !s.Has(CapabilityGzip) || s.Has(CapabilityEncryption) {
+		t.Fatal("unexpected membership")
+	}
+	want := []Capability{CapabilityGzip, CapabilityZstd, CapabilityAppend}
+	if got := s.List(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("list %v, want %v", got, want)
+	}
+	var empty CapabilitySet
+	if empty.Has(CapabilityGzip) || len(empty.List()) != 0 {
+		t.Fatal("nil set is not empty")
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"os"
+)
+
+/**
+Returned by chunk Read after the chunk iterator advanced or was closed.
+ */
+var ErrChunkClosed = errors.New("fs: chunk is closed")
+
+/**
+Chunk is the byte range of the file, SHA-256 is computed while it is read.
+ */
+type Chunk struct {
+	Index  int
+	Offset int64
+	Length int64
+
+	r      io.Reader
+	h      hash.Hash
+	read   int64
+	closed bool
+}
+
+func (c *Chunk) Read(p []byte) (int, error) {
+	if c.closed {
+		return 0, ErrChunkClosed
+	}
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+/*
+Closes the chunk, remaining bytes are not read.
+ */
+func (c *Chunk) Close() error {
+	c.closed = true
+	return nil
+}
+
+/*
+Gets SHA-256 of the chunk, nil until the chunk is read to the end.
+ */
+func (c *Chunk) SHA256() []byte {
+	if c.read < c.Length {
+		return nil
+	}
+	return c.h.Sum(nil)
+}
+
+/**
+ChunkIterator yields chunks of the file bytes as-is, compressed files are not decompressed.
+Chunks must be consumed sequentially, Next closes the previous chunk.
+ */
+type ChunkIterator struct {
+	fd        *os.File
+	size      int64
+	chunkSize int64
+	offset    int64
+	current   *Chunk
+	index     int
+}
+
+/**
+Opens file and creates iterator of chunks limited to chunkSize bytes, the last chunk could be shorter.
+ */
+func ChunkFile(filePath string, chunkSize int64) (*ChunkIterator, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("fs: chunk size must be positive")
+	}
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+	return &ChunkIterator{fd: fd, size: fi.Size(), chunkSize: chunkSize}, nil
+}
+
+/*
+Gets next chunk, returns io.EOF and closes the file when all chunks were returned.
+ */
+func (t *ChunkIterator) Next() (*Chunk, error) {
+	if t.current != nil {
+		t.current.Close()
+		t.current = nil
+	}
+	if t.fd == nil {
+		return nil, io.EOF
+	}
+	if t.offset >= t.size && (t.size > 0 || t.index > 0) {
+		t.Close()
+		return nil, io.EOF
+	}
+	length := t.size - t.offset
+	if length > t.chunkSize {
+		length = t.chunkSize
+	}
+	h := sha256.New()
+	c := &Chunk{
+		Index:  t.index,
+		Offset: t.offset,
+		Length: length,
+		r:      io.TeeReader(io.NewSectionReader(t.fd, t.offset, length), h),
+		h:      h,
+	}
+	t.offset += length
+	t.index++
+	t.current = c
+	return c, nil
+}
+
+/*
+Closes the file, could be called before all chunks are consumed.
+ */
+func (t *ChunkIterator) Close() error {
+	if t.current != nil {
+		t.current.Close()
+		t.current = nil
+	}
+	if t.fd == nil {
+		return nil
+	}
+	err := t.fd.Close()
+	t.fd = nil
+	return err
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeChunkFile(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestChunkFile(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 25)
+	it, err := ChunkFile(writeChunkFile(t, content), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var joined []byte
+	for i := 0; ; i++ {
+		c, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Index != i || c.Offset != int64(i*100) {
+			t.Fatalf("chunk %d at %d", c.Index, c.Offset)
+		}
+		if c.SHA256() != nil {
+			t.Fatal("hash of unread chunk")
+		}
+		data, err := io.ReadAll(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(data)) != c.Length {
+			t.Fatalf("chunk %d has %d bytes, length %d", i, len(data), c.Length)
+		}
+		if sum := sha256.Sum256(data); !bytes.Equal(c.SHA256(), sum[:]) {
+			t.Fatalf("chunk %d hash mismatch", i)
+		}
+		joined = append(joined, data...)
+	}
+	if !bytes.Equal(joined, content) {
+		t.Fatal("chunks do not add up to the file")
+	}
+}
+
+func TestChunkClosedByNext(t *testing.T) {
+	it, err := ChunkFile(writeChunkFile(t, []byte("abcdef")), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	first, _ := it.Next()
+	second, _ := it.Next()
+	if _, err := first.Read(make([]byte, 1)); err != ErrChunkClosed {
+		t.Fatalf("want ErrChunkClosed, got %v", err)
+	}
+	if second.Length != 2 {
+		t.Fatalf("last chunk length %d", second.Length)
+	}
+	if _, err := it.Next(); err != io.EOF {
+		t.Fatalf("want EOF, got %v", err)
+	}
+	if _, err := it.Next(); err != io.EOF {
+		t.Fatalf("want EOF after close, got %v", err)
+	}
+}
+
+func TestChunkFileEmpty(t *testing.T) {
+	it, err := ChunkFile(writeChunkFile(t, nil), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := it.Next()
+	if err != nil || c.Length != 0 {
+		t.Fatalf("want one empty chunk, got %v, %v", c, err)
+	}
+	if sum := sha256.Sum256(nil); !bytes.Equal(c.SHA256(), sum[:]) {
+		t.Fatal("hash of empty chunk")
+	}
+	if _, err := it.Next(); err != io.EOF {
+		t.Fatalf("want EOF, got %v", err)
+	}
+}
+
+func TestChunkFileInvalidSize(t *testing.T) {
+	if _, err := ChunkFile(writeChunkFile(t, nil), 0); err == nil {
+		t.Fatal("zero chunk size accepted")
+	}
+}
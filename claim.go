@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+Returned by ClaimFile when inbox has no complete matching file.
+ */
+var ErrNothingToClaim = errors.New("fs: nothing to claim")
+
+/**
+Returned by Claim methods after Ack or Nack.
+ */
+var ErrClaimDone = errors.New("fs: claim is already acked or nacked")
+
+/**
+Suffix of the marker file that makes inbox file complete regardless of quiet period, e.g. `data.json.gz.ready`.
+ */
+const ReadyMarkerSuffix = ".ready"
+
+/**
+Suffix of the sidecar with the reason written by Nack next to the failed file.
+ */
+const NackReasonSuffix = ".reason"
+
+/**
+Default time since the last modification after which inbox file without marker is complete.
+ */
+const DefaultQuietPeriod = 10 * time.Second
+
+/**
+Claim is the file moved from the inbox to the work directory, owned by the single consumer.
+ */
+type Claim interface {
+
+	/*
+	Gets path of the claimed file in the work directory.
+	 */
+	Path() string
+
+	/*
+	Gets original path of the file in the inbox.
+	 */
+	Source() string
+
+	/*
+	Opens claimed JSON file.
+	 */
+	OpenJson() (JsonReader, error)
+
+	/*
+	Opens claimed protofile.
+	 */
+	OpenProto() (ProtoReader, error)
+
+	/*
+	Opens claimed CSV file.
+	 */
+	OpenCsv(valueProcessors ...CsvValueProcessor) (CsvReader, error)
+
+	/*
+	Moves the file to processed directory.
+	 */
+	Ack(processedDir string) error
+
+	/*
+	Moves the file to failed directory and writes the reason sidecar next to it.
+	 */
+	Nack(failedDir string, reason string) error
+
+}
+
+/**
+Base interface of the exactly-once hand-off between producer and consumer directories.
+ */
+type ClaimService interface {
+
+	/*
+	Renames the oldest complete file matching the glob pattern from inbox in to work directory, rename is the lock, so concurrent claimers never get the same file.
+	File is complete if it has ReadyMarkerSuffix marker, the marker is removed on claim, or was not modified during the quiet period measured by the service clock.
+	Returns ErrNothingToClaim if no file is ready.
+	 */
+	ClaimFile(inboxDir, workDir string, pattern string) (Claim, error)
+
+}
+
+/**
+Renames the oldest complete file matching the glob pattern from inbox in to work directory, ClaimFile of the service is built on it.
+File is complete if it has the ready marker, that is removed after the claim, or was not modified for quiet period before now.
+File renamed by concurrent claimer is skipped, so every file is claimed once. Returns the claimed and the source paths, or ErrNothingToClaim.
+Work file of the same name is never replaced, the claimed file then gets counter before the extensions, e.g. `data.1.json.gz`.
+ */
+func ClaimOldest(inboxDir, workDir, pattern string, now time.Time, quiet time.Duration) (string, string, error) {
+	entries, err := os.ReadDir(inboxDir)
+	if err != nil {
+		return "", "", err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	type candidate struct {
+		name    string
+		modTime time.Time
+	}
+	var list []candidate
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasSuffix(name, ReadyMarkerSuffix) {
+			continue
+		}
+		if ok, err := filepath.Match(pattern, name); err != nil || !ok {
+			if err != nil {
+				return "", "", err
+			}
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if names[name+ReadyMarkerSuffix] || now.Sub(info.ModTime()) >= quiet {
+			list = append(list, candidate{name: name, modTime: info.ModTime()})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].modTime.Equal(list[j].modTime) {
+			return list[i].modTime.Before(list[j].modTime)
+		}
+		return list[i].name < list[j].name
+	})
+	for _, c := range list {
+		source := filepath.Join(inboxDir, c.name)
+		target, err := reserveClaimTarget(workDir, c.name)
+		if err != nil {
+			return "", "", err
+		}
+		if err := os.Rename(source, target); err != nil {
+			os.Remove(target)
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", "", err
+		}
+		os.Remove(source + ReadyMarkerSuffix)
+		return target, source, nil
+	}
+	return "", "", ErrNothingToClaim
+}
+
+/*
+Creates empty file of the target name in the directory with O_EXCL, so the following rename replaces only this reservation and never a file
+of an earlier hand-off. Taken name gets counter before the extensions.
+ */
+func reserveClaimTarget(dir, name string) (string, error) {
+	stem, ext := name, ""
+	if i := strings.IndexByte(name[1:], '.'); i >= 0 {
+		stem, ext = name[:i+1], name[i+1:]
+	}
+	for n := 0; ; n++ {
+		candidate := name
+		if n > 0 {
+			candidate = stem + "." + strconv.Itoa(n) + ext
+		}
+		path := filepath.Join(dir, candidate)
+		fd, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if err == nil {
+			return path, fd.Close()
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+	}
+}
+
+/*
+Moves the file in to the directory without replacing a file of the same name.
+ */
+func moveClaimed(claimedPath, dir string) (string, error) {
+	target, err := reserveClaimTarget(dir, filepath.Base(claimedPath))
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(claimedPath, target); err != nil {
+		os.Remove(target)
+		return "", err
+	}
+	return target, nil
+}
+
+/**
+Moves claimed file in to processed directory, returns the new path. Processed file of the same name is kept, the claimed file gets counter in the name.
+ */
+func AckClaimed(claimedPath, processedDir string) (string, error) {
+	return moveClaimed(claimedPath, processedDir)
+}
+
+/**
+Moves claimed file in to failed directory and writes the reason sidecar next to it, returns the new path. Failed file of the same name is kept.
+ */
+func NackClaimed(claimedPath, failedDir, reason string) (string, error) {
+	target, err := moveClaimed(claimedPath, failedDir)
+	if err != nil {
+		return "", err
+	}
+	return target, os.WriteFile(target+NackReasonSuffix, []byte(reason), 0666)
+}
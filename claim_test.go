@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var claimEpoch = time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func claimDirs(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	inbox, work := filepath.Join(root, "inbox"), filepath.Join(root, "work")
+	for _, dir := range []string{inbox, work} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return inbox, work
+}
+
+func dropFile(t *testing.T, dir, name string, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClaimOldestOrderAndCompleteness(t *testing.T) {
+	inbox, work := claimDirs(t)
+	dropFile(t, inbox, "b.json", claimEpoch.Add(-time.Minute))
+	dropFile(t, inbox, "a.json", claimEpoch.Add(-2*time.Minute))
+	dropFile(t, inbox, "fresh.json", claimEpoch.Add(-time.Second))
+	dropFile(t, inbox, "marked.json", claimEpoch)
+	dropFile(t, inbox, "marked.json"+ReadyMarkerSuffix, claimEpoch)
+	dropFile(t, inbox, "other.csv", claimEpoch.Add(-time.Hour))
+
+	var claimed []string
+	for {
+		path, source, err := ClaimOldest(inbox, work, "*.json", claimEpoch, DefaultQuietPeriod)
+		if err == ErrNothingToClaim {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Dir(path) != work || filepath.Dir(source) != inbox || filepath.Base(path) != filepath.Base(source) {
+			t.Fatalf("claimed %s from %s", path, source)
+		}
+		claimed = append(claimed, filepath.Base(path))
+	}
+	if len(claimed) != 3 || claimed[0] != "a.json" || claimed[1] != "b.json" || claimed[2] != "marked.json" {
+		t.Fatalf("claimed %v", claimed)
+	}
+	if _, err := os.Stat(filepath.Join(inbox, "marked.json"+ReadyMarkerSuffix)); !os.IsNotExist(err) {
+		t.Fatal("ready marker left after claim")
+	}
+	if _, err := os.Stat(filepath.Join(inbox, "fresh.json")); err != nil {
+		t.Fatal("file in quiet period was claimed")
+	}
+}
+
+func TestClaimOldestContention(t *testing.T) {
+	inbox, work := claimDirs(t)
+	const files, claimers = 50, 8
+	for i := 0; i < files; i++ {
+		dropFile(t, inbox, "part-"+strconv.Itoa(i)+".json", claimEpoch.Add(-time.Hour+time.Duration(i%5)*time.Second))
+	}
+	var mu sync.Mutex
+	claims := make(map[string]int)
+	var wg sync.WaitGroup
+	for g := 0; g < claimers; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				path, _, err := ClaimOldest(inbox, work, "*.json", claimEpoch, DefaultQuietPeriod)
+				if err == ErrNothingToClaim {
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				claims[path]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(claims) != files {
+		t.Fatalf("claimed %d of %d files", len(claims), files)
+	}
+	for path, n := range claims {
+		if n != 1 {
+			t.Errorf("%s claimed %d times", path, n)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("claimed file is missing: %v", err)
+		}
+	}
+}
+
+func TestAckAndNackClaimed(t *testing.T) {
+	inbox, work := claimDirs(t)
+	dropFile(t, work, "ok.json", claimEpoch)
+	dropFile(t, work, "bad.json", claimEpoch)
+	processed, err := AckClaimed(filepath.Join(work, "ok.json"), inbox)
+	if err != nil || processed != filepath.Join(inbox, "ok.json") {
+		t.Fatalf("ack %s, %v", processed, err)
+	}
+	failed, err := NackClaimed(filepath.Join(work, "bad.json"), inbox, "line 3: malformed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reason, err := os.ReadFile(failed + NackReasonSuffix)
+	if err != nil || string(reason) != "line 3: malformed" {
+		t.Fatalf("reason %q, %v", reason, err)
+	}
+	if entries, _ := os.ReadDir(work); len(entries) != 0 {
+		t.Fatalf("work directory is not empty: %v", entries)
+	}
+}
+
+func TestClaimOldestKeepsEarlierClaim(t *testing.T) {
+	inbox, work := claimDirs(t)
+	if err := os.WriteFile(filepath.Join(work, "data.json.gz"), []byte("earlier claim"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dropFile(t, work, "data.1.json.gz", claimEpoch)
+	dropFile(t, inbox, "data.json.gz", claimEpoch.Add(-time.Hour))
+	claimed, source, err := ClaimOldest(inbox, work, "*.json.gz", claimEpoch, time.Minute)
+	if err != nil || source != filepath.Join(inbox, "data.json.gz") || claimed != filepath.Join(work, "data.2.json.gz") {
+		t.Fatalf("claimed %s from %s, %v", claimed, source, err)
+	}
+	if content, _ := os.ReadFile(claimed); string(content) != "data.json.gz" {
+		t.Fatalf("claimed content %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(work, "data.json.gz")); string(content) != "earlier claim" {
+		t.Fatalf("earlier claim replaced with %q", content)
+	}
+
+	processed, err := AckClaimed(filepath.Join(work, "data.json.gz"), inbox)
+	if err != nil || processed != filepath.Join(inbox, "data.json.gz") {
+		t.Fatalf("ack %s, %v", processed, err)
+	}
+	dropFile(t, work, "data.json.gz", claimEpoch)
+	if processed, err = AckClaimed(filepath.Join(work, "data.json.gz"), inbox); err != nil || processed != filepath.Join(inbox, "data.1.json.gz") {
+		t.Fatalf("second ack %s, %v", processed, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(inbox, "data.json.gz")); string(content) != "earlier claim" {
+		t.Fatalf("processed file replaced with %q", content)
+	}
+}
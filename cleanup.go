@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+/**
+Returned by cleanup when directory is `/` or shorter than the configured minimum depth.
+ */
+var ErrUnsafeCleanupPath = errors.New("fs: unsafe cleanup path")
+
+/**
+Glob patterns of the files produced by this package: temp files, manifests, proto indexes and journals.
+ */
+var ArtifactPatterns = []string{
+	"*.tmp-*",
+	"*.tmp.*",
+	"*.manifest.json",
+	"*.pbidx",
+	"*.journal",
+}
+
+/**
+Options of the bulk delete operation.
+ */
+type CleanupOptions struct {
+
+	/*
+	Glob patterns matched against the file name, empty means all files.
+	 */
+	Patterns []string
+
+	/*
+	Deletes only files older than this age by modification time.
+	 */
+	MinAge time.Duration
+
+	/*
+	Reports files that would be deleted without deleting them.
+	 */
+	DryRun bool
+
+	/*
+	Walks sub-directories. Symlinks are never followed.
+	 */
+	Recursive bool
+
+	/*
+	Deletes only files matching ArtifactPatterns in addition to Patterns.
+	 */
+	OnlyArtifacts bool
+
+	/*
+	Minimum number of path elements in the absolute directory path, default is 2.
+	 */
+	MinDepth int
+
+}
+
+/**
+Single file deleted by cleanup.
+ */
+type CleanedFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+/**
+Result of the bulk delete operation.
+ */
+type CleanupReport struct {
+
+	/*
+	Deleted files, or files that would be deleted in dry-run mode.
+	 */
+	Files []CleanedFile
+
+	/*
+	Total size of deleted files.
+	 */
+	Bytes int64
+
+	/*
+	True if nothing was deleted because of dry-run mode.
+	 */
+	DryRun bool
+
+}
+
+/**
+Base interface for cleanup of the files in local file system. Rotating writers use the same implementation for retention.
+ */
+type CleanupService interface {
+
+	/*
+	Deletes files in directory matching options.
+	 */
+	CleanupFiles(dir string, opts CleanupOptions) (CleanupReport, error)
+
+	/*
+	Deletes files in directory matching options, stops on context cancellation returning the partial report.
+	 */
+	CleanupFilesContext(ctx context.Context, dir string, opts CleanupOptions) (CleanupReport, error)
+
+}
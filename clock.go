@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"sync"
+	"time"
+)
+
+/**
+Clock is the source of time for every time-dependent feature: rotation, throttling, retention, watch and follow intervals.
+ */
+type Clock interface {
+
+	/*
+	Gets current time.
+	 */
+	Now() time.Time
+
+	/*
+	Waits for the duration and sends current time on the channel.
+	 */
+	After(d time.Duration) <-chan time.Time
+
+	/*
+	Creates ticker with the period, panics if period is not positive.
+	 */
+	Ticker(d time.Duration) Ticker
+
+}
+
+/**
+Ticker delivers ticks of the Clock.
+ */
+type Ticker interface {
+
+	/*
+	Gets channel of ticks.
+	 */
+	C() <-chan time.Time
+
+	/*
+	Stops the ticker.
+	 */
+	Stop()
+
+}
+
+/**
+Clock backed by the time package, default for FileService.
+ */
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Ticker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTicker) Stop() {
+	t.t.Stop()
+}
+
+/**
+FakeClock is manually advanced clock for deterministic tests, timers and tickers fire only in Advance.
+ */
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+type fakeTimer struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	stopped  bool
+}
+
+/**
+Creates fake clock starting at the time.
+ */
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).ch
+}
+
+/*
+Creates fake ticker, panics on non-positive period like time.NewTicker.
+ */
+func (c *FakeClock) Ticker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("fs: non-positive interval for FakeClock.Ticker")
+	}
+	return &fakeTicker{clock: c, t: c.add(d, d)}
+}
+
+/*
+Moves clock forward and fires due timers and tickers, ticks are dropped if previous ones were not consumed.
+ */
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	active := c.waiters[:0]
+	for _, t := range c.waiters {
+		for !t.stopped && !t.deadline.After(c.now) {
+			select {
+			case t.ch <- t.deadline:
+			default:
+			}
+			if t.period == 0 {
+				t.stopped = true
+			} else {
+				t.deadline = t.deadline.Add(t.period)
+			}
+		}
+		if !t.stopped {
+			active = append(active, t)
+		}
+	}
+	c.waiters = active
+}
+
+/*
+Gets number of pending timers and tickers, useful to wait until the code under test is blocked on the clock.
+ */
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func (c *FakeClock) add(d, period time.Duration) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{deadline: c.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	if d <= 0 && period == 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.waiters = append(c.waiters, t)
+	return t
+}
+
+type fakeTicker struct {
+	clock *FakeClock
+	t     *fakeTimer
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.t.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.t.stopped = true
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockTickerPanicsOnNonPositivePeriod(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	for _, d := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("period %v accepted", d)
+				}
+			}()
+			c.Ticker(d)
+		}()
+	}
+	if c.Waiters() != 0 {
+		t.Fatalf("%d waiters left", c.Waiters())
+	}
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFakeClock(start)
+	ticker := c.Ticker(time.Second)
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-ticker.C():
+		t.Fatal("tick before period")
+	default:
+	}
+	c.Advance(3 * time.Second)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Second)) {
+		t.Fatalf("tick at %v", tick)
+	}
+	ticker.Stop()
+	c.Advance(time.Second)
+	if c.Waiters() != 0 {
+		t.Fatalf("stopped ticker is still waiting")
+	}
+}
+
+func TestFakeClockAfter(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	ch := c.After(time.Minute)
+	if c.Waiters() != 1 {
+		t.Fatalf("%d waiters", c.Waiters())
+	}
+	c.Advance(time.Minute)
+	<-ch
+	if c.Waiters() != 0 {
+		t.Fatalf("fired timer is still waiting")
+	}
+	<-c.After(0)
+}
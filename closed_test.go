@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+type syncFailWriter struct {
+	*memJsonWriter
+}
+
+func (w syncFailWriter) Sync() error {
+	return errFakeWrite
+}
+
+func TestAckingJsonWriterAfterClose(t *testing.T) {
+	out := &memJsonWriter{}
+	w := NewAckingJsonWriter(out, 0)
+	if _, err := w.WriteRaw(json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteRaw(json.RawMessage(`{}`)); err != ErrClosed {
+		t.Fatalf("write after close: %v", err)
+	}
+	if _, err := w.Write(map[string]int{"a": 1}); err != ErrClosed {
+		t.Fatalf("write object after close: %v", err)
+	}
+	if err := w.Sync(); err != ErrClosed {
+		t.Fatalf("sync after close: %v", err)
+	}
+	if err := w.Replay(&memJsonWriter{}); err != ErrClosed {
+		t.Fatalf("replay after close: %v", err)
+	}
+	if err := w.Close(); err != ErrClosed {
+		t.Fatalf("double close: %v", err)
+	}
+	if len(out.records) != 1 || w.DurableSeqNo() != 1 {
+		t.Fatalf("written %d, durable %d", len(out.records), w.DurableSeqNo())
+	}
+}
+
+func TestAckingJsonWriterFailedClose(t *testing.T) {
+	out := &memJsonWriter{}
+	w := NewAckingJsonWriter(syncFailWriter{out}, 0)
+	w.WriteRaw(json.RawMessage(`{}`))
+	if err := w.Close(); err != errFakeWrite {
+		t.Fatalf("want sync error, got %v", err)
+	}
+	if !out.closed || w.DurableSeqNo() != 0 || len(w.InFlight()) != 1 {
+		t.Fatalf("closed %v, durable %d, in flight %d", out.closed, w.DurableSeqNo(), len(w.InFlight()))
+	}
+	if _, err := w.WriteRaw(json.RawMessage(`{}`)); err != ErrClosed {
+		t.Fatalf("write after failed close: %v", err)
+	}
+	if err := w.Close(); err != ErrClosed {
+		t.Fatalf("close after failed close: %v", err)
+	}
+}
+
+func TestBytesReaderAfterClose(t *testing.T) {
+	in := newMemJsonReader("{\"a\":1}\n")
+	r := JsonBytesReader(in)
+	if data, err := io.ReadAll(r); err != nil || string(data) != "{\"a\":1}\n" {
+		t.Fatalf("read %q, %v", data, err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != ErrClosed {
+		t.Fatalf("read after close returned latched %v", err)
+	}
+	if err := r.Close(); err != ErrClosed || in.closed != 1 {
+		t.Fatalf("double close: %v, inner closed %d times", err, in.closed)
+	}
+}
+
+func TestValidatingWritersAfterClose(t *testing.T) {
+	csvOut := &memCsvWriter{}
+	cw := NewValidatingCsvWriter(csvOut, []string{"id"}, nil, UniqueColumns([]string{"id"}))
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Write("1"); err != ErrClosed {
+		t.Fatalf("csv write after close: %v", err)
+	}
+	if err := cw.Close(); err != ErrClosed {
+		t.Fatalf("csv double close: %v", err)
+	}
+	if cw.Stats().Rejected != 0 {
+		t.Fatal("write after close was validated")
+	}
+
+	jsonOut := &memJsonWriter{}
+	jw := NewValidatingJsonWriter(jsonOut, nil, NonEmpty([]string{"id"}))
+	jw.Close()
+	if err := jw.WriteRaw(json.RawMessage(`{}`)); err != ErrClosed {
+		t.Fatalf("json write after close: %v", err)
+	}
+	var batchErr *BatchWriteError
+	if err := jw.WriteRawAll([]json.RawMessage{json.RawMessage(`{"id":1}`)}); !errors.As(err, &batchErr) || !errors.Is(err, ErrClosed) {
+		t.Fatalf("json batch after close: %v", err)
+	}
+	if err := jw.Sync(); err != ErrClosed {
+		t.Fatalf("json sync after close: %v", err)
+	}
+	if err := jw.Close(); err != ErrClosed {
+		t.Fatalf("json double close: %v", err)
+	}
+	if jw.Stats().Rejected != 0 {
+		t.Fatal("write after close was validated")
+	}
+}
+
+func TestSpillBufferAfterClose(t *testing.T) {
+	b := NewSpillBuffer(4, t.TempDir(), nil)
+	b.Write([]byte("spill me"))
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte("x")); err != ErrClosed {
+		t.Fatalf("write after close: %v", err)
+	}
+	if _, err := b.Read(make([]byte, 1)); err != ErrClosed {
+		t.Fatalf("read after close: %v", err)
+	}
+	if err := b.Close(); err != ErrClosed {
+		t.Fatalf("double close: %v", err)
+	}
+}
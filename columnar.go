@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "strings"
+
+/**
+File name of the columnar manifest in the output directory.
+ */
+const ColumnarManifestName = "columnar.manifest.json"
+
+/**
+Single column file of the columnar layout.
+ */
+type ColumnarColumn struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+/**
+Manifest of CSV file transposed in to one gzip file per column.
+ */
+type ColumnarManifest struct {
+
+	/*
+	Number of rows without header.
+	 */
+	Rows int64 `json:"rows"`
+
+	/*
+	Columns in the original order, file names are relative to the manifest.
+	 */
+	Columns []ColumnarColumn `json:"columns"`
+}
+
+var columnEscaper = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r")
+
+var columnUnescaper = strings.NewReplacer("\\\\", "\\", "\\n", "\n", "\\r", "\r")
+
+/**
+Escapes value for a single line of column file: backslash, new line and carriage return are backslash-escaped.
+ */
+func EscapeColumnValue(value string) string {
+	return columnEscaper.Replace(value)
+}
+
+/**
+Reverts EscapeColumnValue.
+ */
+func UnescapeColumnValue(line string) string {
+	return columnUnescaper.Replace(line)
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestColumnValueEscapeRoundTrip(t *testing.T) {
+	alphabet := []byte{'\\', 'n', 'r', '\n', '\r', 'a', ','}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		b := make([]byte, r.Intn(12))
+		for j := range b {
+			b[j] = alphabet[r.Intn(len(alphabet))]
+		}
+		value := string(b)
+		line := EscapeColumnValue(value)
+		if strings.ContainsAny(line, "\r\n") {
+			t.Fatalf("escaped %q spans lines: %q", value, line)
+		}
+		if got := UnescapeColumnValue(line); got != value {
+			t.Fatalf("round trip of %q via %q gave %q", value, line, got)
+		}
+	}
+}
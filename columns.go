@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+ColumnType defines type of the column vector.
+ */
+type ColumnType int
+
+const (
+	StringColumn ColumnType = iota
+	Int64Column
+	Float64Column
+	TimeColumn
+)
+
+/**
+CsvCellError is returned when CSV cell does not parse to the column type, row is 1-based without header.
+ */
+type CsvCellError struct {
+	Row    int64
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e *CsvCellError) Error() string {
+	return fmt.Sprintf("fs: row %d column '%s' value '%s': %v", e.Row, e.Column, e.Value, e.Err)
+}
+
+func (e *CsvCellError) Unwrap() error {
+	return e.Err
+}
+
+/**
+Validity bitmap of column vector, bit is set for non-null cells.
+ */
+type Bitmap []uint64
+
+/*
+Checks if cell at index is not null.
+ */
+func (b Bitmap) Get(i int) bool {
+	return b[i>>6]&(1<<uint(i&63)) != 0
+}
+
+/*
+Marks cell at index as not null.
+ */
+func (b Bitmap) Set(i int) {
+	b[i>>6] |= 1 << uint(i&63)
+}
+
+/**
+Typed column vectors of CSV file.
+Memory is 8 bytes per cell for numeric columns, 24 bytes for time columns, string header plus data for string columns,
+plus one bit per cell for validity bitmap. Slices are grown geometrically, so capacity could be up to twice the row count during reading.
+ */
+type ColumnSet interface {
+
+	/*
+	Gets number of rows.
+	 */
+	Rows() int
+
+	/*
+	Gets float64 column vector, nil if column is not of Float64Column type.
+	 */
+	Float64s(name string) []float64
+
+	/*
+	Gets int64 column vector, nil if column is not of Int64Column type.
+	 */
+	Int64s(name string) []int64
+
+	/*
+	Gets string column vector, nil if column is not of StringColumn type.
+	 */
+	Strings(name string) []string
+
+	/*
+	Gets time column vector, nil if column is not of TimeColumn type.
+	 */
+	Times(name string) []time.Time
+
+	/*
+	Gets validity bitmap of the column, empty cells are null.
+	 */
+	Valid(name string) Bitmap
+
+}
+
+/**
+Base interface for columnar reading of CSV files.
+ */
+type ColumnService interface {
+
+	/*
+	Reads selected columns of CSV file in to typed vectors in a single streaming pass.
+	Parse failures return CsvCellError unless NullOnParseError option is set. Time columns use time layout option, default is RFC3339.
+	 */
+	ReadCsvColumns(filePath string, spec map[string]ColumnType) (ColumnSet, error)
+
+	/*
+	Writes one gzip file per column with escaped values separated by new line, and the manifest to the output directory.
+	Column writers share the bounded pool of open file handles.
+	 */
+	TransposeCsvToColumnar(inputPath, outputDir string) (ColumnarManifest, error)
+
+	/*
+	Reconstructs CSV file from columnar layout, the content is equal to the original modulo canonical quoting.
+	 */
+	ComposeCsvFromColumnar(manifestPath, outputPath string) error
+
+}
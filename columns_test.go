@@ -0,0 +1,23 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestBitmap(t *testing.T) {
+	b := make(Bitmap, 3)
+	set := map[int]bool{0: true, 63: true, 64: true, 130: true}
+	for i := range set {
+		b.Set(i)
+	}
+	for i := 0; i < 3*64; i++ {
+		if b.Get(i) != set[i] {
+			t.Fatalf("bit %d is %v", i, b.Get(i))
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"io"
+	"strings"
+)
+
+/**
+Compression codec of the stream.
+ */
+type Compression int
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case Gzip:
+		return "gzip"
+	case Zstd:
+		return "zstd"
+	default:
+		return "none"
+	}
+}
+
+/*
+Gets file extension of the codec, empty for no compression.
+ */
+func (c Compression) Extension() string {
+	switch c {
+	case Gzip:
+		return ".gz"
+	case Zstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+/**
+Detects compression by file extension, `.gz` and `.tgz` are gzip and `.zst` is zstd.
+ */
+func DetectCompression(filePath string) Compression {
+	switch {
+	case strings.HasSuffix(filePath, ".gz"), strings.HasSuffix(filePath, ".tgz"):
+		return Gzip
+	case strings.HasSuffix(filePath, ".zst"):
+		return Zstd
+	default:
+		return NoCompression
+	}
+}
+
+/**
+Base interface for streams with explicit compression codec, bool variants are equal to Gzip or NoCompression.
+ */
+type CodecFileService interface {
+
+	/*
+	Gets zstd compression level, default value is 3.
+	 */
+	ZstdLevel() int
+
+	/*
+	Sets zstd compression level used by every zstd writer.
+	 */
+	SetZstdLevel(level int)
+
+	/*
+	Creates new JSON stream with the codec.
+	 */
+	NewJsonStreamCodec(fd io.Writer, codec Compression) JsonWriter
+
+	/*
+	Opens JSON stream with the codec.
+	 */
+	JsonStreamCodec(fr io.Reader, codec Compression) (JsonReader, error)
+
+	/*
+	Creates new protofile stream with the codec.
+	 */
+	NewProtoStreamCodec(fd io.Writer, codec Compression) ProtoWriter
+
+	/*
+	Opens protofile stream with the codec.
+	 */
+	ProtoStreamCodec(r io.Reader, codec Compression) (ProtoReader, error)
+
+	/*
+	Creates new CSV stream with the codec.
+	 */
+	NewCsvStreamCodec(fw io.Writer, codec Compression, valueProcessors ...CsvValueProcessor) CsvWriter
+
+	/*
+	Opens CSV stream with the codec.
+	 */
+	OpenCsvStreamCodec(fr io.Reader, codec Compression, valueProcessors ...CsvValueProcessor) (CsvStream, error)
+
+}
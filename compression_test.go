@@ -0,0 +1,34 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestDetectCompression(t *testing.T) {
+	for path, want := range map[string]Compression{
+		"data.json":        NoCompression,
+		"data.json.gz":     Gzip,
+		"archive.tgz":      Gzip,
+		"archive.tar.gz":   Gzip,
+		"data.pb.zst":      Zstd,
+		"data.gzip":        NoCompression,
+		"dir.gz/data.json": NoCompression,
+	} {
+		if got := DetectCompression(path); got != want {
+			t.Errorf("DetectCompression(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestCompressionExtension(t *testing.T) {
+	for _, c := range []Compression{NoCompression, Gzip, Zstd} {
+		if c != NoCompression && DetectCompression("file"+c.Extension()) != c {
+			t.Errorf("extension %s of %s is not detected", c.Extension(), c)
+		}
+	}
+}
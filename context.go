@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+)
+
+/**
+CancelledError is returned when operation stopped on context cancellation, it unwraps to ctx.Err().
+ */
+type CancelledError struct {
+	Op      string
+	Records int64
+	Err     error
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("fs: %s cancelled after %d records: %v", e.Op, e.Records, e.Err)
+}
+
+func (e *CancelledError) Unwrap() error {
+	return e.Err
+}
+
+/**
+Base interface for context-aware operations. Context is checked between records, on cancellation partially written outputs are removed
+and CancelledError is returned. Methods without context delegate to these ones with context.Background().
+ */
+type ContextFileService interface {
+
+	/*
+	Creates new JSON file, Write returns CancelledError after context is done.
+	 */
+	NewJsonFileContext(ctx context.Context, filePath string) (JsonWriter, error)
+
+	/*
+	Opens JSON file, Read returns CancelledError after context is done.
+	 */
+	OpenJsonFileContext(ctx context.Context, filePath string) (JsonReader, error)
+
+	/*
+	Splits JSON file in to parts.
+	 */
+	SplitJsonFileContext(ctx context.Context, inputFilePath string, limit int, partitionFn func(int) string) ([]string, error)
+
+	/*
+	Joins JSON files in to one.
+	 */
+	JoinJsonFilesContext(ctx context.Context, outputFilePath string, parts []string) error
+
+	/*
+	Creates new protofile, Write returns CancelledError after context is done.
+	 */
+	NewProtoFileContext(ctx context.Context, filePath string) (ProtoWriter, error)
+
+	/*
+	Opens protofile, ReadTo returns CancelledError after context is done.
+	 */
+	OpenProtoFileContext(ctx context.Context, filePath string) (ProtoReader, error)
+
+	/*
+	Splits protofile in to parts.
+	 */
+	SplitProtoFileContext(ctx context.Context, inputFilePath string, holder proto.Message, limit int, partFn func(int) string) ([]string, error)
+
+	/*
+	Joins protofiles in to one.
+	 */
+	JoinProtoFilesContext(ctx context.Context, outputFilePath string, row proto.Message, parts []string) error
+
+	/*
+	Creates new CSV file, Write returns CancelledError after context is done.
+	 */
+	NewCsvFileContext(ctx context.Context, filePath string, valueProcessors ...CsvValueProcessor) (CsvWriter, error)
+
+	/*
+	Opens CSV file, Read returns CancelledError after context is done.
+	 */
+	OpenCsvFileContext(ctx context.Context, filePath string, valueProcessors ...CsvValueProcessor) (CsvReader, error)
+
+	/*
+	Splits CSV file in to parts.
+	 */
+	SplitCsvFileContext(ctx context.Context, inputFilePath string, limit int, partFn func(int) string) ([]string, error)
+
+	/*
+	Joins CSV files in to one.
+	 */
+	JoinCsvFilesContext(ctx context.Context, outputFilePath string, parts []string) error
+
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/**
+Prefix of the first cell of CSV types row that follows the header.
+ */
+const CsvTypesPrefix = "#types:"
+
+/**
+Returned by join when parts have different types rows.
+ */
+var ErrCsvTypesMismatch = errors.New("fs: CSV parts have different types rows")
+
+/**
+Type of CSV column declared in types row, e.g. `int64` or `timestamp(2006-01-02)`.
+ */
+type CsvType struct {
+	Kind   ColumnType
+	Layout string
+}
+
+func (t CsvType) String() string {
+	switch t.Kind {
+	case Int64Column:
+		return "int64"
+	case Float64Column:
+		return "float64"
+	case TimeColumn:
+		if t.Layout == "" {
+			return "timestamp"
+		}
+		return "timestamp(" + t.Layout + ")"
+	default:
+		return "string"
+	}
+}
+
+/**
+Parses CSV type name.
+ */
+func ParseCsvType(name string) (CsvType, error) {
+	switch {
+	case name == "string":
+		return CsvType{Kind: StringColumn}, nil
+	case name == "int64":
+		return CsvType{Kind: Int64Column}, nil
+	case name == "float64":
+		return CsvType{Kind: Float64Column}, nil
+	case name == "timestamp":
+		return CsvType{Kind: TimeColumn}, nil
+	case strings.HasPrefix(name, "timestamp(") && strings.HasSuffix(name, ")"):
+		return CsvType{Kind: TimeColumn, Layout: name[len("timestamp(") : len(name)-1]}, nil
+	}
+	return CsvType{}, fmt.Errorf("fs: unknown CSV type '%s'", name)
+}
+
+/**
+Parses types row, returns false if the row is not a types row.
+ */
+func ParseCsvTypes(row []string) ([]CsvType, bool, error) {
+	if len(row) == 0 || !strings.HasPrefix(row[0], CsvTypesPrefix) {
+		return nil, false, nil
+	}
+	types := make([]CsvType, len(row))
+	for i, cell := range row {
+		if i == 0 {
+			cell = cell[len(CsvTypesPrefix):]
+		}
+		t, err := ParseCsvType(cell)
+		if err != nil {
+			return nil, true, err
+		}
+		types[i] = t
+	}
+	return types, true, nil
+}
+
+/**
+Formats types row written after the header.
+ */
+func FormatCsvTypes(types []CsvType) []string {
+	row := make([]string, len(types))
+	for i, t := range types {
+		row[i] = t.String()
+	}
+	if len(row) > 0 {
+		row[0] = CsvTypesPrefix + row[0]
+	}
+	return row
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCsvTypesRoundTrip(t *testing.T) {
+	types := []CsvType{{Kind: Int64Column}, {Kind: StringColumn}, {Kind: Float64Column}, {Kind: TimeColumn}, {Kind: TimeColumn, Layout: "2006-01-02"}}
+	row := FormatCsvTypes(types)
+	if want := []string{CsvTypesPrefix + "int64", "string", "float64", "timestamp", "timestamp(2006-01-02)"}; !reflect.DeepEqual(row, want) {
+		t.Fatalf("row %v, want %v", row, want)
+	}
+	parsed, ok, err := ParseCsvTypes(row)
+	if err != nil || !ok {
+		t.Fatalf("ok %v, err %v", ok, err)
+	}
+	if !reflect.DeepEqual(parsed, types) {
+		t.Fatalf("parsed %v, want %v", parsed, types)
+	}
+}
+
+func TestParseCsvTypesDataRow(t *testing.T) {
+	if _, ok, err := ParseCsvTypes([]string{"1", "int64"}); ok || err != nil {
+		t.Fatalf("data row taken as types row: %v", err)
+	}
+	if _, ok, err := ParseCsvTypes([]string{CsvTypesPrefix + "int64", "uint8"}); !ok || err == nil {
+		t.Fatal("unknown type accepted")
+	}
+}
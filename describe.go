@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"google.golang.org/protobuf/proto"
+	"io"
+)
+
+/**
+Default number of records printed by DescribeFile.
+ */
+const DefaultDescribeRecords = 10
+
+/**
+Default maximum width of the value printed by DescribeFile, longer values end with `...`.
+ */
+const DefaultDescribeValueWidth = 80
+
+/**
+Options of the file description.
+ */
+type DescribeOptions struct {
+
+	/*
+	Number of records to print, zero means DefaultDescribeRecords, negative prints summary only.
+	 */
+	Records int
+
+	/*
+	Maximum width of the printed value, zero means DefaultDescribeValueWidth.
+	 */
+	ValueWidth int
+
+	/*
+	Holder of proto records printed by protojson, records are printed as wire trees of DecodeWireMessage if nil.
+	 */
+	Holder proto.Message
+
+	/*
+	Counts records when it requires reading the whole file, otherwise count is printed only from the stats sidecar.
+	 */
+	Count bool
+
+}
+
+/**
+Base interface of human-friendly file inspection.
+ */
+type DescribeService interface {
+
+	/*
+	Prints summary of the file: format, codec, size, record count when cheaply available, header and types of CSV, message type, framing and checksums of proto,
+	then the first records: indented JSON, aligned CSV columns or protojson. Output is deterministic and the file is streamed.
+	Files that could not be decoded, e.g. encrypted or signed by unknown codec, are reported in the summary instead of error.
+	 */
+	DescribeFile(path string, w io.Writer, opts DescribeOptions) error
+
+}
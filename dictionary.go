@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "fmt"
+
+/**
+Trained compression dictionary for zstd codec.
+ */
+type Dictionary struct {
+
+	/*
+	Dictionary ID recorded in file header and zstd frames.
+	 */
+	ID uint32
+
+	/*
+	Raw dictionary content.
+	 */
+	Data []byte
+}
+
+/**
+DictionaryMismatchError is returned when reader was given a dictionary different from the one the file was written with.
+ */
+type DictionaryMismatchError struct {
+	Want uint32
+	Got  uint32
+}
+
+func (e *DictionaryMismatchError) Error() string {
+	return fmt.Sprintf("fs: file requires dictionary %d, got %d", e.Want, e.Got)
+}
+
+/**
+Base interface for compression dictionaries.
+ */
+type DictionaryService interface {
+
+	/*
+	Trains zstd dictionary on records sampled from the files.
+	 */
+	TrainCompressionDictionary(samplePaths []string, maxDictSize int) (Dictionary, error)
+
+}
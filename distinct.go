@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+/**
+Returned when distinct values exceed MaxValues and no output path is configured.
+ */
+var ErrTooManyDistinct = errors.New("fs: too many distinct values")
+
+/**
+Options of distinct values extraction.
+ */
+type DistinctOptions struct {
+
+	/*
+	Maximum number of distinct values kept in memory, zero means unbounded.
+	 */
+	MaxValues int
+
+	/*
+	File where distinct values are streamed in sorted order via external sort once MaxValues is exceeded.
+	 */
+	OutputPath string
+
+	/*
+	Estimates distinct count with HyperLogLog without keeping values.
+	 */
+	Approximate bool
+}
+
+/**
+Result of distinct values extraction.
+ */
+type DistinctResult struct {
+
+	/*
+	Distinct values, nil if they were streamed to the output file or count is approximate.
+	 */
+	Values []string
+
+	/*
+	Output file of distinct values if values were streamed.
+	 */
+	OutputPath string
+
+	/*
+	Number of records scanned.
+	 */
+	Records int64
+
+	/*
+	Number of distinct values, estimated if Approximate is true.
+	 */
+	Distinct int64
+
+	/*
+	Distinct count is estimated.
+	 */
+	Approximate bool
+}
+
+/**
+Base interface for distinct values extraction, key errors are returned as RecordError.
+ */
+type DistinctService interface {
+
+	/*
+	Extracts distinct keys of JSON records.
+	 */
+	DistinctJsonValues(filePath string, keyFn func(json.RawMessage) (string, error), opts DistinctOptions) (DistinctResult, error)
+
+	/*
+	Extracts distinct keys of JSON records, stops on context cancellation.
+	 */
+	DistinctJsonValuesContext(ctx context.Context, filePath string, keyFn func(json.RawMessage) (string, error), opts DistinctOptions) (DistinctResult, error)
+
+	/*
+	Extracts distinct values of CSV column.
+	 */
+	DistinctCsvValues(filePath string, column string, opts DistinctOptions, valueProcessors ...CsvValueProcessor) (DistinctResult, error)
+
+	/*
+	Extracts distinct values of CSV column, stops on context cancellation.
+	 */
+	DistinctCsvValuesContext(ctx context.Context, filePath string, column string, opts DistinctOptions, valueProcessors ...CsvValueProcessor) (DistinctResult, error)
+
+}
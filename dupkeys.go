@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+/**
+DuplicateKeyMode defines behavior of JSON readers for objects with duplicate keys.
+ */
+type DuplicateKeyMode int
+
+const (
+	/*
+	Keeps the last value as encoding/json does, records are not pre-scanned.
+	 */
+	KeepLast DuplicateKeyMode = iota
+
+	/*
+	Keeps the first value of the key.
+	 */
+	KeepFirst
+
+	/*
+	Returns DuplicateKeyError.
+	 */
+	ErrorOnDuplicate
+)
+
+/**
+DuplicateKeyError is returned for record with duplicate key, key is the dot path of the key, array elements are not in the path.
+ */
+type DuplicateKeyError struct {
+	Line int64
+	Key  string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("fs: duplicate key '%s' at line %d", e.Key, e.Line)
+}
+
+/**
+Finds the first duplicate key in the record, returns its dot path or empty string.
+ */
+func FindDuplicateKey(raw json.RawMessage) (string, error) {
+	_, key, err := dedupJsonKeys(raw, "", false)
+	return key, err
+}
+
+/**
+Removes all but the first occurrence of duplicate keys at any depth, record without duplicates is returned as is.
+ */
+func KeepFirstKeys(raw json.RawMessage) (json.RawMessage, error) {
+	out, _, err := dedupJsonKeys(raw, "", true)
+	return out, err
+}
+
+func dedupJsonKeys(raw []byte, path string, remove bool) ([]byte, string, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return raw, "", nil
+	}
+	switch trimmed[0] {
+	case '{':
+		list, err := scanJsonObject(trimmed)
+		if err != nil {
+			return nil, "", err
+		}
+		seen := make(map[string]bool, len(list))
+		changed := false
+		out := []byte{'{'}
+		for _, m := range list {
+			child := m.key
+			if path != "" {
+				child = path + "." + m.key
+			}
+			if seen[m.key] {
+				if !remove {
+					return nil, child, nil
+				}
+				changed = true
+				continue
+			}
+			seen[m.key] = true
+			value := trimmed[m.start:m.end]
+			fixed, dup, err := dedupJsonKeys(value, child, remove)
+			if err != nil || dup != "" {
+				return nil, dup, err
+			}
+			if !bytes.Equal(fixed, value) {
+				changed = true
+			}
+			if len(out) > 1 {
+				out = append(out, ',')
+			}
+			out = append(append(append(out, rawJsonKey(trimmed, m)...), ':'), fixed...)
+		}
+		if !changed {
+			return raw, "", nil
+		}
+		return append(out, '}'), "", nil
+	case '[':
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		if _, err := dec.Token(); err != nil {
+			return nil, "", err
+		}
+		changed := false
+		out := []byte{'['}
+		for dec.More() {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, "", err
+			}
+			fixed, dup, err := dedupJsonKeys(value, path, remove)
+			if err != nil || dup != "" {
+				return nil, dup, err
+			}
+			if !bytes.Equal(fixed, value) {
+				changed = true
+			}
+			if len(out) > 1 {
+				out = append(out, ',')
+			}
+			out = append(out, fixed...)
+		}
+		if !changed {
+			return raw, "", nil
+		}
+		return append(out, ']'), "", nil
+	}
+	return raw, "", nil
+}
+
+/**
+Gets the key of the member as written in the record, so escapes of the original key are kept.
+ */
+func rawJsonKey(raw []byte, m jsonMember) []byte {
+	lead := raw[m.lead:m.start]
+	return lead[bytes.IndexByte(lead, '"') : bytes.LastIndexByte(lead, '"')+1]
+}
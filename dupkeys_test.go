@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestKeepFirstKeys(t *testing.T) {
+	for _, c := range []struct {
+		raw, want string
+	}{
+		{`{"a":1,"b":2}`, `{"a":1,"b":2}`},
+		{`{"a":1,"a":2}`, `{"a":1}`},
+		{`{"<a&b>":1, "x" : {"y":1,"y":2}, "<a&b>":3}`, `{"<a&b>":1,"x":{"y":1}}`},
+		{`{"é":1,"é":2}`, `{"é":1}`},
+		{`{"k\"q":[{"z":1,"z":2}],"k\"q":0}`, `{"k\"q":[{"z":1}]}`},
+	} {
+		got, err := KeepFirstKeys([]byte(c.raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.want {
+			t.Errorf("KeepFirstKeys(%s) = %s, want %s", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestFindDuplicateKey(t *testing.T) {
+	for raw, want := range map[string]string{
+		`{"a":1}`:                       "",
+		`{"a":{"b":1,"b":2}}`:           "a.b",
+		`{"a":[{"c":1},{"c":1,"c":2}]}`: "a.c",
+	} {
+		key, err := FindDuplicateKey([]byte(raw))
+		if err != nil || key != want {
+			t.Errorf("FindDuplicateKey(%s) = %s, %v, want %s", raw, key, err, want)
+		}
+	}
+}
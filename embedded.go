@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+/**
+Prefix of the first line of JSON file with embedded protojson options.
+ */
+const EmbeddedOptionsPrefix = "#fsopts "
+
+/**
+Returned by join when parts embed different options.
+ */
+var ErrConflictingEmbeddedOptions = errors.New("fs: parts embed conflicting options")
+
+/**
+Protojson options embedded in to JSON file by the writer.
+ */
+type EmbeddedOptions struct {
+	UseProtoNames   bool `json:"useProtoNames"`
+	UseEnumNumbers  bool `json:"useEnumNumbers"`
+	EmitUnpopulated bool `json:"emitUnpopulated"`
+}
+
+/**
+Captures embeddable part of marshal options.
+ */
+func NewEmbeddedOptions(mo protojson.MarshalOptions) EmbeddedOptions {
+	return EmbeddedOptions{
+		UseProtoNames:   mo.UseProtoNames,
+		UseEnumNumbers:  mo.UseEnumNumbers,
+		EmitUnpopulated: mo.EmitUnpopulated,
+	}
+}
+
+/*
+Formats options line without trailing new line.
+ */
+func (e EmbeddedOptions) Line() []byte {
+	data, _ := json.Marshal(e)
+	return append([]byte(EmbeddedOptionsPrefix), data...)
+}
+
+/**
+Parses options line, returns false if line is not an options line.
+ */
+func ParseEmbeddedOptions(line []byte) (EmbeddedOptions, bool, error) {
+	var e EmbeddedOptions
+	if !bytes.HasPrefix(line, []byte(EmbeddedOptionsPrefix)) {
+		return e, false, nil
+	}
+	err := json.Unmarshal(line[len(EmbeddedOptionsPrefix):], &e)
+	return e, err == nil, err
+}
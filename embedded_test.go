@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"google.golang.org/protobuf/encoding/protojson"
+	"testing"
+)
+
+func TestEmbeddedOptionsRoundTrip(t *testing.T) {
+	want := NewEmbeddedOptions(protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true, Multiline: true})
+	got, ok, err := ParseEmbeddedOptions(append(want.Line(), '\r', '\n'))
+	if err != nil || !ok || got != want {
+		t.Fatalf("parsed %+v, %v, %v, want %+v", got, ok, err, want)
+	}
+	if want.UseEnumNumbers {
+		t.Fatal("unset option was captured")
+	}
+}
+
+func TestParseEmbeddedOptionsNotOptions(t *testing.T) {
+	if _, ok, err := ParseEmbeddedOptions([]byte(`{"id":1}`)); ok || err != nil {
+		t.Fatalf("record parsed as options: %v, %v", ok, err)
+	}
+	if _, ok, err := ParseEmbeddedOptions([]byte(EmbeddedOptionsPrefix + "{")); ok || err == nil {
+		t.Fatalf("want error for broken options line, got %v, %v", ok, err)
+	}
+}
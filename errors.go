@@ -0,0 +1,290 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+/**
+CallbackPanicError is returned when user callback (partition function, key extractor, processor, hook or transform function) panics.
+The operation runs normal cleanup before returning this error to the caller.
+ */
+type CallbackPanicError struct {
+
+	/*
+	Operation that invoked the callback, for example `SplitJsonFile`.
+	 */
+	Op string
+
+	/*
+	Index of the part or record that was processed when callback panicked.
+	 */
+	Index int
+
+	/*
+	Recovered value.
+	 */
+	Value interface{}
+
+	/*
+	Stack trace of the panicking goroutine.
+	 */
+	Stack []byte
+}
+
+func (e *CallbackPanicError) Error() string {
+	return fmt.Sprintf("fs: callback panic in %s at index %d: %v", e.Op, e.Index, e.Value)
+}
+
+/*
+Returns recovered value if it was an error.
+ */
+func (e *CallbackPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+/**
+Calls user callback and converts a panic in to CallbackPanicError.
+ */
+func SafeCallback(op string, index int, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &CallbackPanicError{Op: op, Index: index, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+/**
+HeaderMismatchError is returned when CSV part has a column that is not expected by the operation.
+ */
+type HeaderMismatchError struct {
+	Part   string
+	Column string
+}
+
+func (e *HeaderMismatchError) Error() string {
+	return fmt.Sprintf("fs: unexpected column '%s' in '%s'", e.Column, e.Part)
+}
+
+/**
+CorruptInputError is returned by readers on malformed input instead of panic, offset is in uncompressed bytes.
+ */
+type CorruptInputError struct {
+	Format FileFormat
+	Offset int64
+	Reason string
+}
+
+func (e *CorruptInputError) Error() string {
+	return fmt.Sprintf("fs: corrupt %s input at offset %d: %s", e.Format, e.Offset, e.Reason)
+}
+
+/**
+Maximum size of raw bytes kept in RecordError of skipped lines.
+ */
+const MaxSkippedRawSize = 256
+
+/**
+RecordError carries location of the record that failed, line is 1-based record number for proto files.
+ */
+type RecordError struct {
+	File string
+	Line int64
+	Raw  []byte
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("fs: %s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+/**
+UnsupportedError is returned when operation is not supported for the file, e.g. random access in to gzip file.
+ */
+type UnsupportedError struct {
+	Op     string
+	Path   string
+	Reason string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("fs: %s is not supported for '%s': %s", e.Op, e.Path, e.Reason)
+}
+
+/**
+Returned by reader Close when gzip CRC or length trailer does not match the content.
+ */
+var ErrTrailerCorrupt = errors.New("fs: compression trailer is corrupt")
+
+/**
+FieldTooLargeError is returned when CSV field exceeds the maximum field size, row and offset point to the opening quote.
+ */
+type FieldTooLargeError struct {
+	Row    int64
+	Offset int64
+	Limit  int
+}
+
+func (e *FieldTooLargeError) Error() string {
+	return fmt.Sprintf("fs: CSV field opened at row %d offset %d exceeds %d bytes", e.Row, e.Offset, e.Limit)
+}
+
+/**
+UnterminatedQuoteError is returned when CSV file ends inside the quoted field, row and offset point to the opening quote.
+ */
+type UnterminatedQuoteError struct {
+	Row    int64
+	Offset int64
+}
+
+func (e *UnterminatedQuoteError) Error() string {
+	return fmt.Sprintf("fs: CSV quote opened at row %d offset %d is not terminated", e.Row, e.Offset)
+}
+
+/**
+TruncatedTailError is returned by proto append when existing file ends in the middle of a frame, offset points to the start of the broken frame.
+ */
+type TruncatedTailError struct {
+	File   string
+	Offset int64
+}
+
+func (e *TruncatedTailError) Error() string {
+	return fmt.Sprintf("fs: '%s' has truncated frame at offset %d", e.File, e.Offset)
+}
+
+/**
+Returned by constructors of the service after Close.
+ */
+var ErrServiceClosed = errors.New("fs: service is closed")
+
+/**
+OpenWritersError lists tracked writers that were still open on strict service Close.
+ */
+type OpenWritersError struct {
+	Files []string
+}
+
+func (e *OpenWritersError) Error() string {
+	return fmt.Sprintf("fs: %d writers are still open: %v", len(e.Files), e.Files)
+}
+
+/**
+Returned by writers and readers used after Close, the first misuse is also reported through the logging hook with the caller location.
+ */
+var ErrClosed = errors.New("fs: use of closed stream")
+
+/**
+ParseError is returned by JSON readers for record that could not be unmarshaled, line is 1-based, offset is in uncompressed bytes at the start of the line.
+ */
+type ParseError struct {
+	Line   int64
+	Offset int64
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("fs: json parse error at line %d (offset %d): %v", e.Line, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ErrRecordTooLarge is returned by JSON readers when line exceeds the maximum record size, line is 1-based.
+ */
+type ErrRecordTooLarge struct {
+	Line  int64
+	Limit int
+}
+
+func (e *ErrRecordTooLarge) Error() string {
+	return fmt.Sprintf("fs: record at line %d exceeds %d bytes", e.Line, e.Limit)
+}
+
+/**
+NumberOverflowError is returned by JSON readers when number does not fit in the integer field of the holder, path is the dot path of the field.
+ */
+type NumberOverflowError struct {
+	Line  int64
+	Path  string
+	Value string
+	Type  string
+}
+
+func (e *NumberOverflowError) Error() string {
+	return fmt.Sprintf("fs: number %s of '%s' at line %d overflows %s", e.Value, e.Path, e.Line, e.Type)
+}
+
+/**
+FileRotatedError is returned by follow readers when the file shrank or was replaced, caller could reopen it.
+ */
+type FileRotatedError struct {
+	Path   string
+	Reason string
+}
+
+func (e *FileRotatedError) Error() string {
+	return fmt.Sprintf("fs: followed file '%s' was rotated: %s", e.Path, e.Reason)
+}
+
+/**
+ErrMessageTooLarge is returned by proto readers when length header exceeds the maximum message size, offset points to the header in uncompressed bytes.
+ */
+type ErrMessageTooLarge struct {
+	Size   uint64
+	Limit  int
+	Offset int64
+}
+
+func (e *ErrMessageTooLarge) Error() string {
+	return fmt.Sprintf("fs: proto message of %d bytes at offset %d exceeds %d bytes", e.Size, e.Offset, e.Limit)
+}
+
+/**
+SkipError is returned by Skip that could not skip all requested records.
+ */
+type SkipError struct {
+	Requested int
+	Skipped   int
+	Err       error
+}
+
+func (e *SkipError) Error() string {
+	return fmt.Sprintf("fs: skipped %d of %d records: %v", e.Skipped, e.Requested, e.Err)
+}
+
+func (e *SkipError) Unwrap() error {
+	return e.Err
+}
+
+/**
+BatchWriteError is returned by batch writes, index is the position of the failed item in the batch.
+ */
+type BatchWriteError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchWriteError) Error() string {
+	return fmt.Sprintf("fs: batch write failed at index %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchWriteError) Unwrap() error {
+	return e.Err
+}
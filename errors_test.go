@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSafeCallbackPanic(t *testing.T) {
+	cause := errors.New("bad key")
+	err := SafeCallback("SplitJsonFile", 7, func() error {
+		panic(cause)
+	})
+	var panicErr *CallbackPanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("want CallbackPanicError, got %v", err)
+	}
+	if panicErr.Op != "SplitJsonFile" || panicErr.Index != 7 || len(panicErr.Stack) == 0 {
+		t.Fatalf("unexpected %+v", panicErr)
+	}
+	if !errors.Is(err, cause) {
+		t.Fatal("panic error value is not unwrapped")
+	}
+}
+
+func TestSafeCallbackNonErrorPanic(t *testing.T) {
+	err := SafeCallback("op", 0, func() error {
+		panic("boom")
+	})
+	var panicErr *CallbackPanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" || panicErr.Unwrap() != nil {
+		t.Fatalf("unexpected %v", err)
+	}
+}
+
+func TestSafeCallbackReturnsError(t *testing.T) {
+	if err := SafeCallback("op", 0, func() error { return io.ErrUnexpectedEOF }); err != io.ErrUnexpectedEOF {
+		t.Fatalf("want callback error, got %v", err)
+	}
+	if err := SafeCallback("op", 0, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVersionedJsonReaderHandlerPanic(t *testing.T) {
+	r := newMemJsonReader("{\"v\":\"1\"}\n\n{\"v\":\"2\"}\n")
+	err := VersionedJsonReader(r, "v", map[string]func(json.RawMessage) error{
+		"1": func(json.RawMessage) error {
+			return nil
+		},
+		"2": func(json.RawMessage) error {
+			panic("handler")
+		},
+	})
+	var panicErr *CallbackPanicError
+	if !errors.As(err, &panicErr) || panicErr.Op != "VersionedJsonReader" || panicErr.Index != 3 {
+		t.Fatalf("want handler panic at line 3, got %v", err)
+	}
+}
+
+/**
+Counts part writers of the split that are not closed.
+ */
+type countingPartWriter struct {
+	*fileJsonWriter
+	open *int
+}
+
+func (w countingPartWriter) Close() error {
+	*w.open--
+	return w.fileJsonWriter.Close()
+}
+
+func TestSplitJsonRecordsPartitionPanic(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 7; i++ {
+		fmt.Fprintf(&input, "{\"n\":%d}\n", i)
+	}
+	for _, panicAt := range []int{0, 2, 3} {
+		dir := t.TempDir()
+		baseline, fdErr := openFds()
+		open := 0
+		create := func(path string) (JsonWriter, error) {
+			file, err := openFaultFile(path, 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			open++
+			return countingPartWriter{fileJsonWriter: newFileJsonWriter(file), open: &open}, nil
+		}
+		parts, err := SplitJsonRecords(newMemJsonReader(input.String()), 2, func(index int) string {
+			if index == panicAt {
+				panic(fmt.Sprintf("partition %d", index))
+			}
+			return filepath.Join(dir, fmt.Sprintf("part-%d.json", index))
+		}, create)
+		var panicErr *CallbackPanicError
+		if !errors.As(err, &panicErr) || panicErr.Op != "SplitJsonFile" || panicErr.Index != panicAt || parts != nil {
+			t.Fatalf("want panic of partition %d, got %v, %v", panicAt, parts, err)
+		}
+		if open != 0 {
+			t.Fatalf("panic at %d: %d part writers left open", panicAt, open)
+		}
+		if fdErr == nil {
+			if n, _ := openFds(); n != baseline {
+				t.Fatalf("panic at %d: %d descriptors open, %d before split", panicAt, n, baseline)
+			}
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("panic at %d: partial parts left %v", panicAt, entries)
+		}
+	}
+}
+
+func TestSplitJsonRecords(t *testing.T) {
+	dir := t.TempDir()
+	parts, err := SplitJsonRecords(newMemJsonReader("1\n2\n3\n4\n5\n"), 2, func(index int) string {
+		return filepath.Join(dir, fmt.Sprintf("part-%d.json", index))
+	}, func(path string) (JsonWriter, error) {
+		file, err := openFaultFile(path, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return newFileJsonWriter(file), nil
+	})
+	if err != nil || len(parts) != 3 {
+		t.Fatalf("parts %v, %v", parts, err)
+	}
+	for i, want := range []string{"1\n2\n", "3\n4\n", "5\n"} {
+		if content, err := os.ReadFile(parts[i]); err != nil || string(content) != want {
+			t.Fatalf("part %d is %q, %v", i, content, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	iofs "io/fs"
+	"strings"
+)
+
+/**
+Prefix of the source that refers to the mounted file system, e.g. `fs://cache/data/part-1.json.gz`.
+ */
+const MountPrefix = "fs://"
+
+/**
+Splits mounted source in to mount name and path in the file system, returns false for local paths.
+ */
+func ParseMountSource(source string) (string, string, bool) {
+	if !strings.HasPrefix(source, MountPrefix) {
+		return "", "", false
+	}
+	name, path, _ := strings.Cut(source[len(MountPrefix):], "/")
+	return name, path, true
+}
+
+/**
+ReaderOrigin tells which source was opened by the failover reader.
+ */
+type ReaderOrigin struct {
+
+	/*
+	Index of the source in the list.
+	 */
+	Index int
+
+	/*
+	Source as it was given.
+	 */
+	Source string
+
+	/*
+	Open-time errors of the sources tried before this one.
+	 */
+	Skipped []SourceError
+}
+
+/**
+SourceError is an open-time error of the single source.
+ */
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e SourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+/**
+AllSourcesFailedError is returned when none of the sources could be opened.
+ */
+type AllSourcesFailedError struct {
+	Errors []SourceError
+}
+
+func (e *AllSourcesFailedError) Error() string {
+	list := make([]string, len(e.Errors))
+	for i, se := range e.Errors {
+		list[i] = se.Error()
+	}
+	return "fs: all sources failed: " + strings.Join(list, "; ")
+}
+
+/*
+Returns errors of all sources for errors.Is and errors.As.
+ */
+func (e *AllSourcesFailedError) Unwrap() []error {
+	list := make([]error, len(e.Errors))
+	for i, se := range e.Errors {
+		list[i] = se.Err
+	}
+	return list
+}
+
+/**
+Base interface of readers that try sources in priority order.
+Source is a local path or MountPrefix path in to the mounted file system. Failover happens only at open time,
+reader errors after the source was chosen are returned as is.
+ */
+type FailoverService interface {
+
+	/*
+	Mounts file system under the name used by MountPrefix sources.
+	 */
+	MountFS(name string, fsys iofs.FS)
+
+	/*
+	Opens the first JSON source that opens, with verify the source must also pass VerifyFileIntegrity.
+	 */
+	OpenJsonFirstOf(sources []string, verify bool) (JsonReader, ReaderOrigin, error)
+
+	/*
+	Opens the first protofile source that opens, with verify the source must also pass VerifyFileIntegrity.
+	 */
+	OpenProtoFirstOf(sources []string, verify bool) (ProtoReader, ReaderOrigin, error)
+
+	/*
+	Opens the first CSV source that opens, with verify the source must also pass VerifyFileIntegrity.
+	 */
+	OpenCsvFirstOf(sources []string, verify bool, valueProcessors ...CsvValueProcessor) (CsvReader, ReaderOrigin, error)
+
+}
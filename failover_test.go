@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestParseMountSource(t *testing.T) {
+	for source, want := range map[string][3]string{
+		"fs://cache/data/part-1.json.gz": {"cache", "data/part-1.json.gz", "true"},
+		"fs://cache":                     {"cache", "", "true"},
+		"/var/data/part-1.json":          {"", "", "false"},
+		"cache/fs://x":                   {"", "", "false"},
+	} {
+		name, path, ok := ParseMountSource(source)
+		if name != want[0] || path != want[1] || (ok != (want[2] == "true")) {
+			t.Errorf("ParseMountSource(%s) = %s, %s, %v", source, name, path, ok)
+		}
+	}
+}
+
+func TestAllSourcesFailedError(t *testing.T) {
+	failure := errors.New("checksum")
+	err := &AllSourcesFailedError{Errors: []SourceError{{Source: "a.json", Err: fs.ErrNotExist}, {Source: "b.json", Err: failure}}}
+	if !errors.Is(err, fs.ErrNotExist) || !errors.Is(err, failure) {
+		t.Fatal("source errors are not unwrapped")
+	}
+	if got := err.Error(); got != "fs: all sources failed: a.json: file does not exist; b.json: checksum" {
+		t.Fatalf("got %s", got)
+	}
+}
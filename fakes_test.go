@@ -0,0 +1,487 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"strings"
+	"sync"
+)
+
+var errFakeWrite = errors.New("fake write failure")
+
+/**
+In-memory JSON writer of the tests, write number fail fails once if set.
+ */
+type memJsonWriter struct {
+	records []json.RawMessage
+	options OptionsSnapshot
+	writes  int
+	fail    int
+	closed  bool
+}
+
+func (w *memJsonWriter) WriteRaw(message json.RawMessage) error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.writes++
+	if w.writes == w.fail {
+		return errFakeWrite
+	}
+	w.records = append(w.records, append(json.RawMessage(nil), message...))
+	return nil
+}
+
+func (w *memJsonWriter) Write(object interface{}) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return w.WriteRaw(data)
+}
+
+func (w *memJsonWriter) WriteRawAll(messages []json.RawMessage) error {
+	for i, m := range messages {
+		if err := w.WriteRaw(m); err != nil {
+			return &BatchWriteError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (w *memJsonWriter) WriteAll(objects []interface{}) error {
+	for i, o := range objects {
+		if err := w.Write(o); err != nil {
+			return &BatchWriteError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (w *memJsonWriter) Stats() WriterStats {
+	return WriterStats{Records: int64(len(w.records))}
+}
+
+func (w *memJsonWriter) Warnings() []Warning {
+	return nil
+}
+
+func (w *memJsonWriter) Options() OptionsSnapshot {
+	return w.options
+}
+
+func (w *memJsonWriter) Sync() error {
+	if w.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (w *memJsonWriter) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+	return nil
+}
+
+/**
+File backend of the tests injecting failures, write number failWrite stores half of the bytes and fails, sync number failSync fails.
+Durable is the file size at the last successful sync, a crash keeps only durable bytes.
+ */
+type faultFile struct {
+	f         *os.File
+	writes    int
+	syncs     int
+	failWrite int
+	failSync  int
+	size      int64
+	durable   int64
+}
+
+func openFaultFile(path string, failWrite, failSync int) (*faultFile, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &faultFile{f: f, failWrite: failWrite, failSync: failSync, size: info.Size(), durable: info.Size()}, nil
+}
+
+func (t *faultFile) Write(p []byte) (int, error) {
+	t.writes++
+	if t.writes == t.failWrite {
+		n, _ := t.f.Write(p[:len(p)/2])
+		t.size += int64(n)
+		return n, errFakeWrite
+	}
+	n, err := t.f.Write(p)
+	t.size += int64(n)
+	return n, err
+}
+
+func (t *faultFile) Sync() error {
+	t.syncs++
+	if t.syncs == t.failSync {
+		return errFakeWrite
+	}
+	if err := t.f.Sync(); err != nil {
+		return err
+	}
+	t.durable = t.size
+	return nil
+}
+
+/*
+Closes the file and drops bytes that were not synced, like a crash of the process and the host.
+ */
+func (t *faultFile) crash() error {
+	t.f.Close()
+	return os.Truncate(t.f.Name(), t.durable)
+}
+
+/**
+NDJSON writer of the tests over buffered sticky writer of the fault file, small buffer makes records reach the file between syncs.
+ */
+type fileJsonWriter struct {
+	file    *faultFile
+	sticky  *StickyWriter
+	buf     *bufio.Writer
+	records int64
+	closed  bool
+}
+
+func newFileJsonWriter(file *faultFile) *fileJsonWriter {
+	sticky := NewStickyWriter(file)
+	return &fileJsonWriter{file: file, sticky: sticky, buf: bufio.NewWriterSize(sticky, 64)}
+}
+
+func (w *fileJsonWriter) WriteRaw(message json.RawMessage) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if err := w.sticky.Err(); err != nil {
+		return err
+	}
+	if _, err := w.buf.Write(append(append([]byte(nil), message...), '\n')); err != nil {
+		return err
+	}
+	w.records++
+	return nil
+}
+
+func (w *fileJsonWriter) Write(object interface{}) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return w.WriteRaw(data)
+}
+
+func (w *fileJsonWriter) WriteRawAll(messages []json.RawMessage) error {
+	for i, m := range messages {
+		if err := w.WriteRaw(m); err != nil {
+			return &BatchWriteError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (w *fileJsonWriter) WriteAll(objects []interface{}) error {
+	for i, o := range objects {
+		if err := w.Write(o); err != nil {
+			return &BatchWriteError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (w *fileJsonWriter) Stats() WriterStats {
+	return WriterStats{Records: w.records}
+}
+
+func (w *fileJsonWriter) Warnings() []Warning {
+	return nil
+}
+
+func (w *fileJsonWriter) Options() OptionsSnapshot {
+	return OptionsSnapshot{}
+}
+
+func (w *fileJsonWriter) Sync() error {
+	if w.closed {
+		return ErrClosed
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+func (w *fileJsonWriter) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	err := w.Sync()
+	w.closed = true
+	if cerr := w.file.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+/**
+In-memory CSV writer of the tests.
+ */
+type memCsvWriter struct {
+	rows   [][]string
+	closed bool
+}
+
+func (w *memCsvWriter) Write(values ...string) error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.rows = append(w.rows, append([]string(nil), values...))
+	return nil
+}
+
+func (w *memCsvWriter) Stats() WriterStats {
+	return WriterStats{Records: int64(len(w.rows))}
+}
+
+func (w *memCsvWriter) Warnings() []Warning {
+	return nil
+}
+
+func (w *memCsvWriter) Options() OptionsSnapshot {
+	return OptionsSnapshot{}
+}
+
+func (w *memCsvWriter) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+	return nil
+}
+
+/**
+In-memory CSV reader of the tests, the first row is the header.
+ */
+type memCsvReader struct {
+	rows   [][]string
+	types  []CsvType
+	pos    int
+	closed int
+}
+
+func (r *memCsvReader) ReadHeader() (CsvFile, error) {
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+	return &memCsvFile{r: r, header: header, index: index}, nil
+}
+
+func (r *memCsvReader) Read() ([]string, error) {
+	if r.closed > 0 {
+		return nil, ErrClosed
+	}
+	if r.pos >= len(r.rows) {
+		return nil, io.EOF
+	}
+	r.pos++
+	return r.rows[r.pos-1], nil
+}
+
+func (r *memCsvReader) Rows() iter.Seq2[[]string, error] {
+	return CsvRows(r)
+}
+
+func (r *memCsvReader) Stats() ReaderStats {
+	return ReaderStats{}
+}
+
+func (r *memCsvReader) Warnings() []Warning {
+	return nil
+}
+
+func (r *memCsvReader) Options() OptionsSnapshot {
+	return OptionsSnapshot{}
+}
+
+func (r *memCsvReader) Close() error {
+	r.closed++
+	return nil
+}
+
+type memCsvFile struct {
+	r      *memCsvReader
+	header []string
+	index  map[string]int
+}
+
+func (f *memCsvFile) Header() []string {
+	return f.header
+}
+
+func (f *memCsvFile) Index() map[string]int {
+	return f.index
+}
+
+func (f *memCsvFile) Types() []CsvType {
+	return f.r.types
+}
+
+func (f *memCsvFile) Next() (CsvRecord, error) {
+	values, err := f.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	return memCsvRecord{index: f.index, values: values}, nil
+}
+
+type memCsvRecord struct {
+	index  map[string]int
+	values []string
+}
+
+func (r memCsvRecord) Record() []string {
+	return r.values
+}
+
+func (r memCsvRecord) Field(name string, def string) string {
+	if i, ok := r.index[name]; ok && i < len(r.values) {
+		return r.values[i]
+	}
+	return def
+}
+
+func (r memCsvRecord) Fields() map[string]string {
+	fields := make(map[string]string, len(r.index))
+	for name := range r.index {
+		fields[name] = r.Field(name, "")
+	}
+	return fields
+}
+
+/**
+In-memory NDJSON reader of the tests, blank lines are skipped but counted by Position.
+ */
+type memJsonReader struct {
+	lines  []string
+	line   int64
+	offset int64
+	closed int
+}
+
+func newMemJsonReader(content string) *memJsonReader {
+	return &memJsonReader{lines: strings.Split(strings.TrimSuffix(content, "\n"), "\n")}
+}
+
+func (r *memJsonReader) ReadRaw() (json.RawMessage, error) {
+	if r.closed > 0 {
+		return nil, ErrClosed
+	}
+	for r.line < int64(len(r.lines)) {
+		text := r.lines[r.line]
+		r.line++
+		r.offset += int64(len(text)) + 1
+		if strings.TrimSpace(text) != "" {
+			return json.RawMessage(text), nil
+		}
+	}
+	return nil, io.EOF
+}
+
+func (r *memJsonReader) Read(holder interface{}) error {
+	raw, err := r.ReadRaw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, holder)
+}
+
+func (r *memJsonReader) All() iter.Seq2[json.RawMessage, error] {
+	return JsonRecords(r)
+}
+
+func (r *memJsonReader) Position() (int64, int64) {
+	return r.line, r.offset
+}
+
+func (r *memJsonReader) Skipped() []RecordError {
+	return nil
+}
+
+func (r *memJsonReader) EmbeddedOptions() (EmbeddedOptions, bool) {
+	return EmbeddedOptions{}, false
+}
+
+func (r *memJsonReader) Stats() ReaderStats {
+	return ReaderStats{}
+}
+
+func (r *memJsonReader) Warnings() []Warning {
+	return nil
+}
+
+func (r *memJsonReader) Options() OptionsSnapshot {
+	return OptionsSnapshot{}
+}
+
+func (r *memJsonReader) Close() error {
+	r.closed++
+	return nil
+}
+
+/**
+Metrics hook of the tests, safe for concurrent use.
+ */
+type memMetrics struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	gauges   map[string]int64
+}
+
+func (m *memMetrics) IncCounter(name string, delta int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counters == nil {
+		m.counters = make(map[string]int64)
+	}
+	m.counters[name] += delta
+}
+
+func (m *memMetrics) SetGauge(name string, value int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gauges == nil {
+		m.gauges = make(map[string]int64)
+	}
+	m.gauges[name] = value
+}
+
+func (m *memMetrics) counter(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[name]
+}
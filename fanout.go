@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+)
+
+/**
+Route of JSON fanout, records matching predicate are written to the path.
+ */
+type Route struct {
+	Name      string
+	Predicate func(json.RawMessage) (bool, error)
+	Path      string
+}
+
+/**
+Route of CSV fanout, every output gets the header of the input.
+ */
+type CsvRoute struct {
+	Name      string
+	Predicate func(CsvRecord) (bool, error)
+	Path      string
+}
+
+/**
+Route of proto fanout, predicate gets the holder with the current record.
+ */
+type ProtoRoute struct {
+	Name      string
+	Predicate func(proto.Message) (bool, error)
+	Path      string
+}
+
+/**
+Result of the fanout.
+ */
+type FanoutReport struct {
+
+	/*
+	Records written by route name.
+	 */
+	Routes map[string]int64
+
+	/*
+	Records written to the default path.
+	 */
+	Default int64
+
+	/*
+	Unmatched records dropped because default path is empty.
+	 */
+	Dropped int64
+
+	/*
+	Records skipped on predicate error with SkipPredicateErrors option.
+	 */
+	Skipped int64
+
+	/*
+	Records read from the input.
+	 */
+	Records int64
+}
+
+/**
+Base interface of single-pass routing of records in to many outputs.
+Routes are evaluated in order and the first match wins, MultiMatch option writes record to every matching route.
+Unmatched records go to the default path or are dropped if it is empty. Predicate error is returned as RecordError,
+or the record is skipped with SkipPredicateErrors option.
+ */
+type FanoutService interface {
+
+	/*
+	Routes records of JSON file.
+	 */
+	FanoutJsonFile(inputPath string, routes []Route, defaultPath string) (FanoutReport, error)
+
+	/*
+	Routes rows of CSV file.
+	 */
+	FanoutCsvFile(inputPath string, routes []CsvRoute, defaultPath string) (FanoutReport, error)
+
+	/*
+	Routes records of protofile, outputs keep the header of the input.
+	 */
+	FanoutProtoFile(inputPath string, holder proto.Message, routes []ProtoRoute, defaultPath string) (FanoutReport, error)
+
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"strings"
+)
+
+/**
+MaskMode defines how field mask is applied to the message.
+ */
+type MaskMode int
+
+const (
+	/*
+	Clears every field not covered by the mask.
+	 */
+	KeepMask MaskMode = iota
+
+	/*
+	Clears fields covered by the mask.
+	 */
+	RemoveMask
+)
+
+func (m MaskMode) String() string {
+	switch m {
+	case KeepMask:
+		return "keep"
+	case RemoveMask:
+		return "remove"
+	default:
+		return "unknown"
+	}
+}
+
+/**
+UnknownMaskPathError is returned when mask paths do not resolve in the message descriptor.
+Repeated and map fields are allowed only as the last element of the path.
+ */
+type UnknownMaskPathError struct {
+	Message string
+	Paths   []string
+}
+
+func (e *UnknownMaskPathError) Error() string {
+	return fmt.Sprintf("fs: unknown field mask paths for '%s': %s", e.Message, strings.Join(e.Paths, ", "))
+}
+
+type maskTree map[protoreflect.Name]maskTree
+
+/**
+FieldMasker applies validated field mask to messages of one type.
+ */
+type FieldMasker struct {
+	desc protoreflect.MessageDescriptor
+	tree maskTree
+	mode MaskMode
+}
+
+/**
+Validates mask against the descriptor and compiles it for repeated use.
+ */
+func NewFieldMasker(desc protoreflect.MessageDescriptor, mask *fieldmaskpb.FieldMask, mode MaskMode) (*FieldMasker, error) {
+	tree := maskTree{}
+	var unknown []string
+	for _, path := range mask.GetPaths() {
+		if !addMaskPath(tree, desc, path) {
+			unknown = append(unknown, path)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownMaskPathError{Message: string(desc.FullName()), Paths: unknown}
+	}
+	return &FieldMasker{desc: desc, tree: tree, mode: mode}, nil
+}
+
+func addMaskPath(tree maskTree, desc protoreflect.MessageDescriptor, path string) bool {
+	name, rest, nested := cutPath(path)
+	fd := desc.Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return false
+	}
+	if !nested {
+		tree[fd.Name()] = nil
+		return true
+	}
+	if fd.IsList() || fd.IsMap() || fd.Message() == nil {
+		return false
+	}
+	sub, ok := tree[fd.Name()]
+	if ok && sub == nil {
+		return addMaskPath(maskTree{}, fd.Message(), rest)
+	}
+	if !ok {
+		sub = maskTree{}
+		tree[fd.Name()] = sub
+	}
+	return addMaskPath(sub, fd.Message(), rest)
+}
+
+/*
+Applies mask to the message in place, message must have the descriptor of the masker.
+ */
+func (t *FieldMasker) Apply(m proto.Message) error {
+	msg := m.ProtoReflect()
+	if msg.Descriptor().FullName() != t.desc.FullName() {
+		return &ErrMessageTypeMismatch{Want: string(t.desc.FullName()), Got: string(msg.Descriptor().FullName())}
+	}
+	applyMask(msg, t.tree, t.mode)
+	return nil
+}
+
+func applyMask(m protoreflect.Message, tree maskTree, mode MaskMode) {
+	var clear, nested []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := tree[fd.Name()]
+		if fd.IsExtension() {
+			ok = false
+		}
+		switch {
+		case ok && sub != nil:
+			nested = append(nested, fd)
+		case ok == (mode == RemoveMask):
+			clear = append(clear, fd)
+		}
+		return true
+	})
+	for _, fd := range clear {
+		m.Clear(fd)
+	}
+	for _, fd := range nested {
+		applyMask(m.Mutable(fd).Message(), tree[fd.Name()], mode)
+	}
+	if mode == KeepMask {
+		m.SetUnknown(nil)
+	}
+}
+
+/**
+Validates and applies mask to the single message.
+ */
+func ApplyFieldMask(m proto.Message, mask *fieldmaskpb.FieldMask, mode MaskMode) error {
+	t, err := NewFieldMasker(m.ProtoReflect().Descriptor(), mask, mode)
+	if err != nil {
+		return err
+	}
+	return t.Apply(m)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/typepb"
+	"testing"
+)
+
+func maskedType() *typepb.Type {
+	return &typepb.Type{
+		Name:          "Order",
+		Fields:        []*typepb.Field{{Name: "id", Number: 1}, {Name: "amount", Number: 2}},
+		Oneofs:        []string{"payment"},
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "order.proto"},
+		Syntax:        typepb.Syntax_SYNTAX_PROTO3,
+	}
+}
+
+func applyMaskOrFail(t *testing.T, m proto.Message, mode MaskMode, paths ...string) {
+	t.Helper()
+	if err := ApplyFieldMask(m, &fieldmaskpb.FieldMask{Paths: paths}, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFieldMaskNested(t *testing.T) {
+	kept := maskedType()
+	applyMaskOrFail(t, kept, KeepMask, "source_context.file_name")
+	if !proto.Equal(kept, &typepb.Type{SourceContext: &sourcecontextpb.SourceContext{FileName: "order.proto"}}) {
+		t.Fatalf("keep gave %v", kept)
+	}
+	removed := maskedType()
+	applyMaskOrFail(t, removed, RemoveMask, "source_context.file_name", "syntax")
+	want := maskedType()
+	want.SourceContext.FileName, want.Syntax = "", typepb.Syntax_SYNTAX_PROTO2
+	if !proto.Equal(removed, want) {
+		t.Fatalf("remove gave %v", removed)
+	}
+}
+
+func TestFieldMaskWholeFieldCoversNested(t *testing.T) {
+	for _, paths := range [][]string{
+		{"source_context", "source_context.file_name"},
+		{"source_context.file_name", "source_context"},
+	} {
+		m := maskedType()
+		applyMaskOrFail(t, m, KeepMask, paths...)
+		if m.SourceContext.GetFileName() != "order.proto" || m.Name != "" {
+			t.Fatalf("mask %v gave %v", paths, m)
+		}
+	}
+}
+
+func TestFieldMaskRepeated(t *testing.T) {
+	kept := maskedType()
+	applyMaskOrFail(t, kept, KeepMask, "fields", "oneofs")
+	if len(kept.Fields) != 2 || len(kept.Oneofs) != 1 || kept.Name != "" || kept.SourceContext != nil {
+		t.Fatalf("keep gave %v", kept)
+	}
+	removed := maskedType()
+	applyMaskOrFail(t, removed, RemoveMask, "fields")
+	if len(removed.Fields) != 0 || removed.Name != "Order" {
+		t.Fatalf("remove gave %v", removed)
+	}
+}
+
+func TestFieldMaskMap(t *testing.T) {
+	value := func() *structpb.Value {
+		s, _ := structpb.NewStruct(map[string]interface{}{"a": 1, "b": "x"})
+		return structpb.NewStructValue(s)
+	}
+	kept := value()
+	applyMaskOrFail(t, kept, KeepMask, "struct_value.fields")
+	if len(kept.GetStructValue().GetFields()) != 2 {
+		t.Fatalf("keep gave %v", kept)
+	}
+	removed := value()
+	applyMaskOrFail(t, removed, RemoveMask, "struct_value.fields")
+	if removed.GetStructValue() == nil || len(removed.GetStructValue().GetFields()) != 0 {
+		t.Fatalf("remove gave %v", removed)
+	}
+}
+
+func TestFieldMaskUnknownPaths(t *testing.T) {
+	err := ApplyFieldMask(maskedType(), &fieldmaskpb.FieldMask{Paths: []string{"name", "nope", "fields.name", "name.x", "source_context.nope"}}, KeepMask)
+	var pathErr *UnknownMaskPathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("want UnknownMaskPathError, got %v", err)
+	}
+	if pathErr.Message != "google.protobuf.Type" || len(pathErr.Paths) != 4 || pathErr.Paths[0] != "nope" {
+		t.Fatalf("unexpected %+v", pathErr)
+	}
+	if _, err := NewFieldMasker((&structpb.Struct{}).ProtoReflect().Descriptor(), &fieldmaskpb.FieldMask{Paths: []string{"fields.a"}}, KeepMask); !errors.As(err, &pathErr) {
+		t.Fatalf("map key path accepted: %v", err)
+	}
+}
+
+func TestFieldMaskerTypeMismatch(t *testing.T) {
+	masker, err := NewFieldMasker((&typepb.Type{}).ProtoReflect().Descriptor(), &fieldmaskpb.FieldMask{Paths: []string{"name"}}, KeepMask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mismatch *ErrMessageTypeMismatch
+	if err := masker.Apply(&typepb.Field{}); !errors.As(err, &mismatch) {
+		t.Fatalf("want type mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/**
+Size of the file head and tail hashed by fingerprint.
+ */
+const FingerprintBlockSize = 64 * 1024
+
+/**
+Returned when fingerprint string could not be parsed.
+ */
+var ErrInvalidFingerprint = errors.New("fs: invalid fingerprint")
+
+/**
+Fingerprint identifies file content cheaply, hashes are SHA-256 truncated to 16 bytes.
+ */
+type Fingerprint struct {
+
+	/*
+	Size of the file, or of the decompressed content for logical fingerprint.
+	 */
+	Size int64
+
+	/*
+	Hashes of the first and the last FingerprintBlockSize bytes.
+	 */
+	Head []byte
+	Tail []byte
+
+	/*
+	Hash of the full content, nil unless full fingerprint was requested.
+	 */
+	Full []byte
+
+	/*
+	Resolved physical path of the fingerprinted file, not part of Equal, changes when symlink is switched.
+	Fingerprint with path is formatted as v2 string, so the path survives runs.
+	 */
+	Path string
+}
+
+/*
+Formats fingerprint as compact string `v1:size:head:tail[:full]`, or `v2:size:head:tail:[full]:path` with hex encoded path if the path is set.
+ */
+func (f Fingerprint) String() string {
+	version := "v1:"
+	if f.Path != "" {
+		version = "v2:"
+	}
+	s := version + strconv.FormatInt(f.Size, 10) + ":" + hex.EncodeToString(f.Head) + ":" + hex.EncodeToString(f.Tail)
+	if f.Path != "" {
+		return s + ":" + hex.EncodeToString(f.Full) + ":" + hex.EncodeToString([]byte(f.Path))
+	}
+	if f.Full != nil {
+		s += ":" + hex.EncodeToString(f.Full)
+	}
+	return s
+}
+
+/*
+Compares size, head and tail hashes, and full hashes if both fingerprints have them.
+ */
+func (f Fingerprint) Equal(other Fingerprint) bool {
+	if !f.WeakEqual(other) {
+		return false
+	}
+	if f.Full != nil && other.Full != nil {
+		return bytes.Equal(f.Full, other.Full)
+	}
+	return true
+}
+
+/*
+Compares only size, head and tail hashes.
+ */
+func (f Fingerprint) WeakEqual(other Fingerprint) bool {
+	return f.Size == other.Size && bytes.Equal(f.Head, other.Head) && bytes.Equal(f.Tail, other.Tail)
+}
+
+/*
+Checks if the fingerprint changed since the previous one, resolved paths are compared only if the previous fingerprint has the path.
+ */
+func (f Fingerprint) Changed(previous Fingerprint) bool {
+	return !f.Equal(previous) || previous.Path != "" && f.Path != previous.Path
+}
+
+/**
+Parses fingerprint from v1 or v2 string produced by String.
+ */
+func ParseFingerprint(s string) (Fingerprint, error) {
+	var f Fingerprint
+	parts := strings.Split(s, ":")
+	switch {
+	case parts[0] == "v1" && (len(parts) == 4 || len(parts) == 5):
+	case parts[0] == "v2" && len(parts) == 6:
+		path, err := hex.DecodeString(parts[5])
+		if err != nil || len(path) == 0 {
+			return f, ErrInvalidFingerprint
+		}
+		f.Path = string(path)
+		parts = parts[:5]
+		if parts[4] == "" {
+			parts = parts[:4]
+		}
+	default:
+		return f, ErrInvalidFingerprint
+	}
+	var err error
+	if f.Size, err = strconv.ParseInt(parts[1], 10, 64); err != nil || f.Size < 0 {
+		return f, ErrInvalidFingerprint
+	}
+	if f.Head, err = hex.DecodeString(parts[2]); err != nil {
+		return f, ErrInvalidFingerprint
+	}
+	if f.Tail, err = hex.DecodeString(parts[3]); err != nil {
+		return f, ErrInvalidFingerprint
+	}
+	if len(parts) == 5 {
+		if f.Full, err = hex.DecodeString(parts[4]); err != nil {
+			return f, ErrInvalidFingerprint
+		}
+	}
+	return f, nil
+}
+
+/**
+Base interface for change detection of files between pipeline runs.
+Gzip files are fingerprinted by compressed bytes unless LogicalFingerprint option is set.
+ */
+type FingerprintService interface {
+
+	/*
+	Computes fingerprint of the file, full hash is computed with FullFingerprint option.
+	 */
+	FingerprintFile(filePath string) (Fingerprint, error)
+
+	/*
+	Computes fingerprint of the file and compares it with the previous one using Changed,
+	so changed resolved path counts as change only if the previous fingerprint has the path.
+	 */
+	ChangedSince(filePath string, previous Fingerprint) (bool, Fingerprint, error)
+
+}
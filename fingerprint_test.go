@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestFingerprintRoundTrip(t *testing.T) {
+	for _, f := range []Fingerprint{
+		{Size: 0, Head: []byte{}, Tail: []byte{}},
+		{Size: 1 << 40, Head: []byte{1, 2}, Tail: []byte{3, 4}},
+		{Size: 5, Head: []byte{1}, Tail: []byte{2}, Full: []byte{0xff, 0}},
+		{Size: 5, Head: []byte{1}, Tail: []byte{2}, Path: "/data/2024-01-05:x/part.json"},
+		{Size: 5, Head: []byte{1}, Tail: []byte{2}, Full: []byte{7}, Path: "/data/part.json"},
+	} {
+		parsed, err := ParseFingerprint(f.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Equal(f) || parsed.String() != f.String() || (parsed.Full == nil) != (f.Full == nil) || parsed.Path != f.Path {
+			t.Fatalf("parsed %s, want %s", parsed, f)
+		}
+	}
+}
+
+func TestFingerprintEqual(t *testing.T) {
+	weak := Fingerprint{Size: 5, Head: []byte{1}, Tail: []byte{2}, Path: "/a"}
+	full := weak
+	full.Full, full.Path = []byte{9}, "/b"
+	other := full
+	other.Full = []byte{8}
+	if !weak.Equal(full) || !full.Equal(weak) {
+		t.Fatal("fingerprint without full hash must compare by head and tail")
+	}
+	if full.Equal(other) || !full.WeakEqual(other) {
+		t.Fatal("different full hashes must differ only in Equal")
+	}
+	tail := weak
+	tail.Tail = []byte{3}
+	if weak.WeakEqual(tail) {
+		t.Fatal("different tails are weak equal")
+	}
+}
+
+func TestFingerprintChangedAfterRoundTrip(t *testing.T) {
+	current := Fingerprint{Size: 5, Head: []byte{1}, Tail: []byte{2}, Path: "/data/2024-01-05/part.json"}
+	previous, err := ParseFingerprint(current.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Changed(previous) {
+		t.Fatal("unchanged file reported as changed after round trip")
+	}
+	flipped := current
+	flipped.Path = "/data/2024-01-06/part.json"
+	if !flipped.Changed(previous) {
+		t.Fatal("switched symlink target not reported")
+	}
+	legacy, err := ParseFingerprint("v1:5:01:02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Changed(legacy) {
+		t.Fatal("fingerprint saved without path reported as changed")
+	}
+	grown := current
+	grown.Size = 6
+	if !grown.Changed(legacy) {
+		t.Fatal("changed content not reported")
+	}
+}
+
+func TestParseFingerprintInvalid(t *testing.T) {
+	for _, s := range []string{"", "v1:1:aa", "v2:1:aa:bb", "v1:x:aa:bb", "v1:-1:aa:bb", "v1:1:zz:bb", "v1:1:aa:bb:cc:dd", "v1:1:aa:bb:c", "v2:1:aa:bb::", "v2:1:aa:bb::zz", "v2:1:aa:bb:cc"} {
+		if _, err := ParseFingerprint(s); err != ErrInvalidFingerprint {
+			t.Errorf("ParseFingerprint(%q) = %v", s, err)
+		}
+	}
+}
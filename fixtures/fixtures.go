@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+/**
+Package fixtures generates synthetic files for tests and benchmarks through the FileService, so codecs and options of the service apply.
+Generators take explicit random source and are deterministic for a given seed.
+ */
+package fixtures
+
+import (
+	"fmt"
+	"github.com/sprintframework/fs"
+	"google.golang.org/protobuf/proto"
+	"math/rand"
+	"time"
+)
+
+/**
+Number of records written by one batch write.
+ */
+const batchSize = 1024
+
+/**
+Writes n JSON records produced by generator.
+ */
+func GenerateJsonFile(service fs.FileService, path string, n int64, gen func(i int64) interface{}) error {
+	w, err := service.NewJsonFile(path)
+	if err != nil {
+		return err
+	}
+	batch := make([]interface{}, 0, batchSize)
+	for i := int64(0); i < n; i++ {
+		batch = append(batch, gen(i))
+		if len(batch) == batchSize || i == n-1 {
+			if err := w.WriteAll(batch); err != nil {
+				w.Close()
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	return w.Close()
+}
+
+/**
+Writes CSV file with header and n rows produced by generator.
+ */
+func GenerateCsvFile(service fs.FileService, path string, header []string, n int64, gen func(i int64) []string) error {
+	w, err := service.NewCsvFile(path)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(header...); err != nil {
+		w.Close()
+		return err
+	}
+	for i := int64(0); i < n; i++ {
+		if err := w.Write(gen(i)...); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+/**
+Writes n proto records produced by generator, records are written in batches, so generator must not reuse the message.
+ */
+func GenerateProtoFile(service fs.FileService, path string, n int64, gen func(i int64) proto.Message) error {
+	w, err := service.NewProtoFile(path)
+	if err != nil {
+		return err
+	}
+	batch := make([]proto.Message, 0, batchSize)
+	for i := int64(0); i < n; i++ {
+		batch = append(batch, gen(i))
+		if len(batch) == batchSize || i == n-1 {
+			if err := w.WriteAll(batch); err != nil {
+				w.Close()
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	return w.Close()
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+/**
+Creates generator of random alphanumeric strings of the size.
+ */
+func RandomStrings(r *rand.Rand, size int) func() string {
+	return func() string {
+		b := make([]byte, size)
+		for i := range b {
+			b[i] = letters[r.Intn(len(letters))]
+		}
+		return string(b)
+	}
+}
+
+/**
+Creates generator of keys in [0, n) with zipfian distribution, s > 1 is the skew, larger s makes few keys more frequent.
+Panics if s is not above 1 or n is zero, since rand.NewZipf has no generator for them.
+ */
+func ZipfKeys(r *rand.Rand, s float64, n uint64) func() uint64 {
+	if !(s > 1) || n == 0 {
+		panic(fmt.Sprintf("fixtures: ZipfKeys needs s > 1 and n > 0, got s=%v n=%d", s, n))
+	}
+	z := rand.NewZipf(r, s, 1, n-1)
+	return func() uint64 {
+		return z.Uint64()
+	}
+}
+
+/**
+Creates generator of uniformly distributed timestamps in [from, to).
+ */
+func Timestamps(r *rand.Rand, from, to time.Time) func() time.Time {
+	span := int64(to.Sub(from))
+	return func() time.Time {
+		if span <= 0 {
+			return from
+		}
+		return from.Add(time.Duration(r.Int63n(span)))
+	}
+}
+
+/**
+Creates generator of uniformly distributed timestamps within the window before the current time of the clock, e.g. service Clock.
+ */
+func RecentTimestamps(clock fs.Clock, r *rand.Rand, window time.Duration) func() time.Time {
+	return func() time.Time {
+		now := clock.Now()
+		return Timestamps(r, now.Add(-window), now)()
+	}
+}
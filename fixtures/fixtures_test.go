@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fixtures
+
+import (
+	"encoding/json"
+	"github.com/sprintframework/fs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordingService struct {
+	fs.FileService
+	json  *recordingJsonWriter
+	proto *recordingProtoWriter
+}
+
+func (s *recordingService) NewJsonFile(filePath string) (fs.JsonWriter, error) {
+	s.json = &recordingJsonWriter{}
+	return s.json, nil
+}
+
+func (s *recordingService) NewProtoFile(filePath string) (fs.ProtoWriter, error) {
+	s.proto = &recordingProtoWriter{}
+	return s.proto, nil
+}
+
+type recordingJsonWriter struct {
+	fs.JsonWriter
+	batches []int
+	records []interface{}
+	closed  bool
+}
+
+func (w *recordingJsonWriter) WriteAll(objects []interface{}) error {
+	w.batches = append(w.batches, len(objects))
+	w.records = append(w.records, objects...)
+	return nil
+}
+
+func (w *recordingJsonWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+type recordingProtoWriter struct {
+	fs.ProtoWriter
+	batches []int
+	records []proto.Message
+}
+
+func (w *recordingProtoWriter) WriteAll(messages []proto.Message) error {
+	w.batches = append(w.batches, len(messages))
+	w.records = append(w.records, messages...)
+	return nil
+}
+
+func (w *recordingProtoWriter) Close() error {
+	return nil
+}
+
+func TestGenerateJsonFileBatches(t *testing.T) {
+	s := &recordingService{}
+	n := int64(2*batchSize + 5)
+	if err := GenerateJsonFile(s, "out.json", n, func(i int64) interface{} {
+		return json.RawMessage(`{}`)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{batchSize, batchSize, 5}; !reflect.DeepEqual(s.json.batches, want) || !s.json.closed {
+		t.Fatalf("batches %v, want %v", s.json.batches, want)
+	}
+	if err := GenerateJsonFile(s, "empty.json", 0, nil); err != nil || len(s.json.batches) != 0 {
+		t.Fatalf("empty file batches %v, err %v", s.json.batches, err)
+	}
+}
+
+func TestGenerateProtoFileOrder(t *testing.T) {
+	s := &recordingService{}
+	if err := GenerateProtoFile(s, "out.pb", batchSize+1, func(i int64) proto.Message {
+		return wrapperspb.Int64(i)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range s.proto.records {
+		if m.(*wrapperspb.Int64Value).Value != int64(i) {
+			t.Fatalf("record %d is %v", i, m)
+		}
+	}
+}
+
+func TestGeneratorsDeterministic(t *testing.T) {
+	a, b := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+	sa, sb := RandomStrings(a, 8), RandomStrings(b, 8)
+	za, zb := ZipfKeys(a, 1.2, 100), ZipfKeys(b, 1.2, 100)
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	ta, tb := Timestamps(a, from, from.Add(time.Hour)), Timestamps(b, from, from.Add(time.Hour))
+	for i := 0; i < 100; i++ {
+		s := sa()
+		if s != sb() || len(s) != 8 {
+			t.Fatalf("strings differ at %d", i)
+		}
+		k := za()
+		if k != zb() || k >= 100 {
+			t.Fatalf("keys differ at %d", i)
+		}
+		ts := ta()
+		if !ts.Equal(tb()) || ts.Before(from) || !ts.Before(from.Add(time.Hour)) {
+			t.Fatalf("timestamps differ at %d", i)
+		}
+	}
+}
+
+func TestZipfKeysInvalid(t *testing.T) {
+	for _, c := range []struct {
+		s float64
+		n uint64
+	}{{1, 10}, {0.5, 10}, {2, 0}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("s=%v n=%d accepted", c.s, c.n)
+				}
+			}()
+			ZipfKeys(rand.New(rand.NewSource(1)), c.s, c.n)
+		}()
+	}
+	if k := ZipfKeys(rand.New(rand.NewSource(1)), 2, 1)(); k != 0 {
+		t.Fatalf("single key generator returned %d", k)
+	}
+}
+
+func TestRecentTimestamps(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	gen := RecentTimestamps(fs.NewFakeClock(now), rand.New(rand.NewSource(1)), time.Minute)
+	for i := 0; i < 100; i++ {
+		if ts := gen(); ts.After(now) || ts.Before(now.Add(-time.Minute)) {
+			t.Fatalf("timestamp %v out of window", ts)
+		}
+	}
+}
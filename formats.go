@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+/**
+FileFormat is the record format of the file recognized by extension.
+ */
+type FileFormat int
+
+const (
+	UnknownFormat FileFormat = iota
+	JsonFormat
+	CsvFormat
+	TsvFormat
+	ProtoFormat
+)
+
+func (f FileFormat) String() string {
+	switch f {
+	case JsonFormat:
+		return "json"
+	case CsvFormat:
+		return "csv"
+	case TsvFormat:
+		return "tsv"
+	case ProtoFormat:
+		return "proto"
+	default:
+		return "unknown"
+	}
+}
+
+/**
+Gets default extension policy, the map of file extension to the file format.
+TSV extension implies tab as a separator.
+ */
+func DefaultExtensions() map[string]FileFormat {
+	return map[string]FileFormat{
+		".json":   JsonFormat,
+		".jsonl":  JsonFormat,
+		".ndjson": JsonFormat,
+		".csv":    CsvFormat,
+		".tsv":    TsvFormat,
+		".pb":     ProtoFormat,
+		".bin":    ProtoFormat,
+	}
+}
+
+/**
+Extension policy used by format detection and output name composition.
+ */
+type ExtensionPolicy interface {
+
+	/*
+	Registers or overrides file extension for the format. Extension must start with dot, e.g. `.jsonl`.
+	 */
+	RegisterExtension(ext string, format FileFormat)
+
+	/*
+	Detects file format by extension, compression extension like `.gz` or `.zst` is skipped.
+	Returns UnknownFormat and false if extension is not registered.
+	Unknown extensions do not affect direct Open/New calls, they are still permissive.
+	 */
+	DetectFormat(filePath string) (FileFormat, bool)
+
+	/*
+	Gets the primary extension of the format used to compose output file names.
+	 */
+	FormatExtension(format FileFormat) string
+
+}
+
+/**
+On-disk format variant produced by the package, the list is the cross-language compatibility contract.
+Implementation golden tests write and verify `testdata/golden/<Name>` for every variant.
+ */
+type FormatVariant struct {
+	Name       string
+	Format     FileFormat
+	Gzip       bool
+	Zstd       bool
+	Header     bool
+	FrameFlags bool
+	Framing    Framing
+	Checksums  bool
+}
+
+/**
+Gets all on-disk format variants, zstd variants join the list with the zstd codec since every variant must have a pinned golden file.
+ */
+func FormatVariants() []FormatVariant {
+	return []FormatVariant{
+		{Name: "json.ndjson", Format: JsonFormat},
+		{Name: "json.ndjson.gz", Format: JsonFormat, Gzip: true},
+		{Name: "json-options.ndjson", Format: JsonFormat, Header: true},
+		{Name: "csv.csv", Format: CsvFormat},
+		{Name: "csv.csv.gz", Format: CsvFormat, Gzip: true},
+		{Name: "tsv.tsv", Format: TsvFormat},
+		{Name: "proto.pb", Format: ProtoFormat},
+		{Name: "proto.pb.gz", Format: ProtoFormat, Gzip: true},
+		{Name: "proto-header.pb", Format: ProtoFormat, Header: true},
+		{Name: "proto-flags.pb", Format: ProtoFormat, Header: true, FrameFlags: true},
+		{Name: "proto-crc.pb", Format: ProtoFormat, Header: true, Checksums: true},
+		{Name: "proto-crc-flags.pb", Format: ProtoFormat, Header: true, FrameFlags: true, Checksums: true},
+		{Name: "proto-delimited.pb", Format: ProtoFormat, Framing: VarintFraming},
+		{Name: "proto-delimited.pb.gz", Format: ProtoFormat, Gzip: true, Framing: VarintFraming},
+	}
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultExtensions(t *testing.T) {
+	extensions := DefaultExtensions()
+	for ext, want := range map[string]FileFormat{".jsonl": JsonFormat, ".ndjson": JsonFormat, ".tsv": TsvFormat, ".bin": ProtoFormat} {
+		if extensions[ext] != want {
+			t.Errorf("extension %s is %s, want %s", ext, extensions[ext], want)
+		}
+	}
+	extensions[".custom"] = CsvFormat
+	if _, ok := DefaultExtensions()[".custom"]; ok {
+		t.Fatal("default extensions are shared between calls")
+	}
+}
+
+func TestFormatVariantNames(t *testing.T) {
+	extensions := DefaultExtensions()
+	seen := make(map[string]bool)
+	for _, v := range FormatVariants() {
+		if seen[v.Name] {
+			t.Errorf("duplicate variant %s", v.Name)
+		}
+		seen[v.Name] = true
+		compression := DetectCompression(v.Name)
+		if (compression == Gzip) != v.Gzip || (compression == Zstd) != v.Zstd {
+			t.Errorf("variant %s has compression %s", v.Name, compression)
+		}
+		name := strings.TrimSuffix(v.Name, compression.Extension())
+		if got := extensions[filepath.Ext(name)]; got != v.Format {
+			t.Errorf("variant %s is detected as %s, want %s", v.Name, got, v.Format)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"math"
+)
+
+/**
+Framing defines the length prefix of proto records.
+ */
+type Framing int
+
+const (
+	/*
+	BigEndian uint32 length before every record, default framing of protofiles.
+	 */
+	LengthFraming Framing = iota
+
+	/*
+	Varint length before every record, compatible with writeDelimitedTo of Java and protodelim of Go.
+	Varint files have no header, since the header could not be told from the record.
+	 */
+	VarintFraming
+)
+
+func (f Framing) String() string {
+	switch f {
+	case LengthFraming:
+		return "length"
+	case VarintFraming:
+		return "varint"
+	default:
+		return "unknown"
+	}
+}
+
+/**
+Writes varint-delimited record.
+ */
+func WriteDelimited(w io.Writer, payload []byte) error {
+	prefix := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64), uint64(len(payload)))
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+/**
+Reads varint-delimited record, returns io.EOF at clean end of stream and io.ErrUnexpectedEOF for truncated record.
+Length above maxSize returns ErrMessageTooLarge with offset of the record before allocating the payload, zero maxSize disables the check.
+Payload buffer grows with the data actually read, so a corrupt length never allocates more than the stream holds.
+ */
+func ReadDelimited(r *bufio.Reader, maxSize int, offset int64) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && size > uint64(maxSize) {
+		return nil, &ErrMessageTooLarge{Size: size, Limit: maxSize, Offset: offset}
+	}
+	return readPayload(r, size)
+}
+
+/**
+Payloads up to this size are allocated at once, larger ones grow in chunks while being read.
+ */
+const payloadChunkSize = 64 * 1024
+
+func readPayload(r io.Reader, size uint64) ([]byte, error) {
+	if size <= payloadChunkSize {
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return payload, nil
+	}
+	if size > math.MaxInt64 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var buf bytes.Buffer
+	buf.Grow(payloadChunkSize)
+	n, err := io.CopyN(&buf, r, int64(size))
+	if uint64(n) < size {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/**
+Appends proto frame of the framing and header layout to dst, flags are written only if the header has FrameFlags.
+ */
+func AppendProtoFrame(dst []byte, framing Framing, h ProtoHeader, payload []byte, flags FrameFlags) []byte {
+	if framing == VarintFraming {
+		dst = binary.AppendUvarint(dst, uint64(len(payload)))
+	} else {
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(payload)))
+	}
+	if h.Checksums {
+		dst = binary.BigEndian.AppendUint32(dst, RecordChecksum(payload))
+	}
+	if h.FrameFlags {
+		dst = append(dst, byte(flags))
+	}
+	return append(dst, payload...)
+}
+
+/**
+Appends frames of the messages to dst, it backs WriteAll of proto writers that write the result with a single flush.
+Failure returns dst without frames of the batch and BatchWriteError with index of the message, so nothing of the batch is written.
+ */
+func AppendProtoBatch(dst []byte, framing Framing, h ProtoHeader, messages []proto.Message, opts proto.MarshalOptions) ([]byte, error) {
+	mark := len(dst)
+	var payload []byte
+	for i, message := range messages {
+		var err error
+		payload, err = opts.MarshalAppend(payload[:0], message)
+		if err != nil {
+			return dst[:mark], &BatchWriteError{Index: i, Err: err}
+		}
+		dst = AppendProtoFrame(dst, framing, h, payload, 0)
+	}
+	return dst, nil
+}
+
+/**
+ProtoFrameReader decodes frames of protofile body after the header, layout is given by framing and header flags.
+Payload is returned as stored, records with RecordCompressed flag are decompressed by the caller.
+Truncated frame returns CorruptInputError, size above the maximum returns ErrMessageTooLarge and checksum failure ErrChecksumMismatch.
+ */
+type ProtoFrameReader struct {
+	r       *bufio.Reader
+	framing Framing
+	header  ProtoHeader
+	maxSize int
+	offset  int64
+	index   int64
+}
+
+/**
+Creates frame reader, offset of the first frame is the size of the header already consumed, zero maxSize disables the size check.
+ */
+func NewProtoFrameReader(r *bufio.Reader, framing Framing, h ProtoHeader, maxSize int, offset int64) *ProtoFrameReader {
+	return &ProtoFrameReader{r: r, framing: framing, header: h, maxSize: maxSize, offset: offset}
+}
+
+/*
+Gets offset of the next frame in uncompressed bytes.
+ */
+func (t *ProtoFrameReader) Offset() int64 {
+	return t.offset
+}
+
+/*
+Reads next frame, returns io.EOF at clean end of stream.
+ */
+func (t *ProtoFrameReader) Next() ([]byte, FrameFlags, error) {
+	start := t.offset
+	counter := &countingByteReader{r: t.r}
+	var size uint64
+	if t.framing == VarintFraming {
+		v, err := binary.ReadUvarint(counter)
+		t.offset += counter.n
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		if err != nil {
+			return nil, 0, t.corrupt(start, "invalid varint size")
+		}
+		size = v
+	} else {
+		var prefix [4]byte
+		n, err := io.ReadFull(t.r, prefix[:])
+		t.offset += int64(n)
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		if err != nil {
+			return nil, 0, t.corrupt(start, "truncated size")
+		}
+		size = uint64(binary.BigEndian.Uint32(prefix[:]))
+	}
+	if t.maxSize > 0 && size > uint64(t.maxSize) {
+		return nil, 0, &ErrMessageTooLarge{Size: size, Limit: t.maxSize, Offset: start}
+	}
+	var tail [5]byte
+	var want uint32
+	var flags FrameFlags
+	extra := 0
+	if t.header.Checksums {
+		extra += 4
+	}
+	if t.header.FrameFlags {
+		extra++
+	}
+	if extra > 0 {
+		n, err := io.ReadFull(t.r, tail[:extra])
+		t.offset += int64(n)
+		if err != nil {
+			return nil, 0, t.corrupt(start, "truncated frame header")
+		}
+		if t.header.Checksums {
+			want = binary.BigEndian.Uint32(tail[:4])
+		}
+		if t.header.FrameFlags {
+			flags = FrameFlags(tail[extra-1])
+		}
+	}
+	payload, err := readPayload(t.r, size)
+	if err != nil {
+		return nil, 0, t.corrupt(start, "truncated payload")
+	}
+	t.offset += int64(size)
+	index := t.index
+	t.index++
+	if t.header.Checksums {
+		if got := RecordChecksum(payload); got != want {
+			return nil, 0, &ErrChecksumMismatch{Index: index, Want: want, Got: got}
+		}
+	}
+	return payload, flags, nil
+}
+
+func (t *ProtoFrameReader) corrupt(offset int64, reason string) error {
+	return &CorruptInputError{Format: ProtoFormat, Offset: offset, Reason: reason}
+}
+
+type countingByteReader struct {
+	r io.ByteReader
+	n int64
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func frameReader(body []byte, framing Framing, h ProtoHeader, maxSize int) *ProtoFrameReader {
+	return NewProtoFrameReader(bufio.NewReader(bytes.NewReader(body)), framing, h, maxSize, 10)
+}
+
+func TestProtoFrameReaderLayouts(t *testing.T) {
+	payloads := [][]byte{[]byte("a"), {}, bytes.Repeat([]byte("x"), 3*payloadChunkSize)}
+	for _, framing := range []Framing{LengthFraming, VarintFraming} {
+		for _, h := range []ProtoHeader{{}, {FrameFlags: true}, {Checksums: true}, {FrameFlags: true, Checksums: true}} {
+			var body []byte
+			var offsets []int64
+			for i, p := range payloads {
+				offsets = append(offsets, 10+int64(len(body)))
+				body = AppendProtoFrame(body, framing, h, p, FrameFlags(i))
+			}
+			r := frameReader(body, framing, h, 0)
+			for i, want := range payloads {
+				if r.Offset() != offsets[i] {
+					t.Fatalf("%s %+v: frame %d at offset %d, want %d", framing, h, i, r.Offset(), offsets[i])
+				}
+				payload, flags, err := r.Next()
+				if err != nil || !bytes.Equal(payload, want) {
+					t.Fatalf("%s %+v: frame %d: %d bytes, %v", framing, h, i, len(payload), err)
+				}
+				if h.FrameFlags && flags != FrameFlags(i) {
+					t.Fatalf("%s %+v: frame %d flags %d", framing, h, i, flags)
+				}
+			}
+			if _, _, err := r.Next(); err != io.EOF {
+				t.Fatalf("%s %+v: want EOF, got %v", framing, h, err)
+			}
+		}
+	}
+}
+
+func TestProtoFrameReaderTruncated(t *testing.T) {
+	h := ProtoHeader{FrameFlags: true, Checksums: true}
+	first := AppendProtoFrame(nil, LengthFraming, h, []byte("first"), 0)
+	body := AppendProtoFrame(first, LengthFraming, h, []byte("second"), 0)
+	for cut := len(first) + 1; cut < len(body); cut++ {
+		r := frameReader(body[:cut], LengthFraming, h, 0)
+		if _, _, err := r.Next(); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := r.Next()
+		var corrupt *CorruptInputError
+		if !errors.As(err, &corrupt) || corrupt.Offset != 10+int64(len(first)) || corrupt.Format != ProtoFormat {
+			t.Fatalf("cut at %d: want corrupt frame at %d, got %v", cut, 10+len(first), err)
+		}
+	}
+}
+
+func TestProtoFrameReaderChecksumMismatch(t *testing.T) {
+	h := ProtoHeader{Checksums: true}
+	body := AppendProtoFrame(nil, VarintFraming, h, []byte("good"), 0)
+	body = AppendProtoFrame(body, VarintFraming, h, []byte("evil"), 0)
+	body[len(body)-1] ^= 1
+	r := frameReader(body, VarintFraming, h, 0)
+	r.Next()
+	_, _, err := r.Next()
+	var mismatch *ErrChecksumMismatch
+	if !errors.As(err, &mismatch) || mismatch.Index != 1 || mismatch.Want != RecordChecksum([]byte("evil")) {
+		t.Fatalf("want checksum mismatch of record 1, got %v", err)
+	}
+}
+
+func TestProtoFrameReaderTooLarge(t *testing.T) {
+	h := ProtoHeader{Checksums: true}
+	for _, framing := range []Framing{LengthFraming, VarintFraming} {
+		first := AppendProtoFrame(nil, framing, h, []byte("small"), 0)
+		body := AppendProtoFrame(first, framing, h, []byte(strings.Repeat("x", 100)), 0)
+		r := frameReader(body, framing, h, 99)
+		if _, _, err := r.Next(); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := r.Next()
+		var tooLarge *ErrMessageTooLarge
+		if !errors.As(err, &tooLarge) || tooLarge.Size != 100 || tooLarge.Limit != 99 || tooLarge.Offset != 10+int64(len(first)) {
+			t.Fatalf("%s: want ErrMessageTooLarge at offset %d, got %v", framing, 10+len(first), err)
+		}
+	}
+}
+
+func TestDelimitedRoundTrip(t *testing.T) {
+	payloads := [][]byte{{}, []byte("small"), bytes.Repeat([]byte("y"), payloadChunkSize+1), bytes.Repeat([]byte("z"), 5*payloadChunkSize)}
+	var buf bytes.Buffer
+	for _, p := range payloads {
+		if err := WriteDelimited(&buf, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := bufio.NewReader(&buf)
+	var offset int64
+	for i, want := range payloads {
+		got, err := ReadDelimited(r, 0, offset)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("payload %d: %d bytes, %v", i, len(got), err)
+		}
+		offset += int64(len(binary.AppendUvarint(nil, uint64(len(got))))) + int64(len(got))
+	}
+	if _, err := ReadDelimited(r, 0, offset); err != io.EOF {
+		t.Fatalf("want EOF at clean end, got %v", err)
+	}
+}
+
+func TestDelimitedLimits(t *testing.T) {
+	var buf bytes.Buffer
+	WriteDelimited(&buf, []byte("small"))
+	WriteDelimited(&buf, []byte(strings.Repeat("x", 100)))
+	full := buf.Bytes()
+
+	r := bufio.NewReader(bytes.NewReader(full))
+	if _, err := ReadDelimited(r, 99, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ReadDelimited(r, 99, 6)
+	var tooLarge *ErrMessageTooLarge
+	if !errors.As(err, &tooLarge) || tooLarge.Size != 100 || tooLarge.Limit != 99 || tooLarge.Offset != 6 {
+		t.Fatalf("want ErrMessageTooLarge at offset 6, got %v", err)
+	}
+	truncated := bufio.NewReader(bytes.NewReader(full[:len(full)-1]))
+	ReadDelimited(truncated, 0, 0)
+	if _, err := ReadDelimited(truncated, 0, 6); err != io.ErrUnexpectedEOF {
+		t.Fatalf("truncated payload: want ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := ReadDelimited(bufio.NewReader(bytes.NewReader(full[:0])), 0, 0); err != io.EOF {
+		t.Fatalf("empty input: want EOF, got %v", err)
+	}
+}
+
+func TestDelimitedHugeLengthDoesNotAllocate(t *testing.T) {
+	huge := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := ReadDelimited(bufio.NewReader(bytes.NewReader(huge)), 0, 0); err != io.ErrUnexpectedEOF {
+		t.Fatalf("want ErrUnexpectedEOF, got %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Fatalf("%d bytes allocated for a frame without data", n)
+	}
+}
+
+func TestAppendProtoBatch(t *testing.T) {
+	h := ProtoHeader{Checksums: true}
+	prefix := []byte("header")
+	messages := []proto.Message{wrapperspb.String("a"), wrapperspb.String("b"), wrapperspb.String("c")}
+	body, err := AppendProtoBatch(prefix, VarintFraming, h, messages, proto.MarshalOptions{})
+	if err != nil || !bytes.HasPrefix(body, prefix) {
+		t.Fatal(err)
+	}
+	r := frameReader(body[len(prefix):], VarintFraming, h, 0)
+	for _, want := range []string{"a", "b", "c"} {
+		payload, _, err := r.Next()
+		got := &wrapperspb.StringValue{}
+		if err != nil || proto.Unmarshal(payload, got) != nil || got.Value != want {
+			t.Fatalf("want %s, got %v, %v", want, got, err)
+		}
+	}
+
+	messages[1] = wrapperspb.String("\xff")
+	body, err = AppendProtoBatch(prefix, VarintFraming, h, messages, proto.MarshalOptions{})
+	var batchErr *BatchWriteError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 || !bytes.Equal(body, prefix) {
+		t.Fatalf("want failure at 1 and nothing of the batch appended, got %q, %v", body, err)
+	}
+}
@@ -6,13 +6,43 @@
 package fs
 
 import (
+	"context"
 	"encoding/json"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"io"
+	"iter"
+	"math/rand"
 	"os"
+	"time"
 )
 
+/**
+Buffer size value that enables adaptive buffers.
+ */
+const Adaptive = -1
+
+const (
+	AdaptiveMinBufferSize = 16 * 1024
+	AdaptiveMaxBufferSize = 16 * 1024 * 1024
+)
+
+/**
+Default maximum size of CSV field.
+ */
+const DefaultMaxCsvFieldSize = 4 * 1024 * 1024
+
+/**
+Default maximum size of JSON line.
+ */
+const DefaultMaxRecordSize = 64 * 1024 * 1024
+
+/**
+Default maximum size of proto message.
+ */
+const DefaultMaxProtoMessageSize = 64 * 1024 * 1024
+
 /**
 FileService interface is used to inject this module to applications
  */
@@ -20,6 +50,36 @@ type FileService interface {
 	JsonFileService
 	ProtoFileService
 	CsvFileService
+	ExtensionPolicy
+	CleanupService
+	SortService
+	IntegrityService
+	ArchiveService
+	MergeService
+	CompactionService
+	ColumnService
+	TopNService
+	FingerprintService
+	StrictPolicy
+	CsvUpdateService
+	SidecarService
+	TransactionService
+	DictionaryService
+	ContextFileService
+	FSFileService
+	DistinctService
+	CodecFileService
+	FailoverService
+	FanoutService
+	ClaimService
+	MigrateService
+	DescribeService
+	PackService
+
+	/*
+	Creates derived service that applies options to every operation, the current service is not changed.
+	 */
+	With(options ...Option) FileService
 
 	/*
 	Gets current buffer size, default value is 64k
@@ -28,9 +88,74 @@ type FileService interface {
 
 	/*
 	Sets current buffer size, that would be used on each file opening or creation. Particularly useful for gzip files.
+	Adaptive value makes readers grow from AdaptiveMinBufferSize up to AdaptiveMaxBufferSize on large records and writers size buffer by recent record sizes.
 	 */
 	SetBufferSize(rwBufSize int)
 
+	/*
+	Gets gzip compression level, default value is gzip.DefaultCompression
+	 */
+	CompressionLevel() int
+
+	/*
+	Sets gzip compression level used by every gzip writer including split parts and join outputs.
+	Returns error if level is out of gzip.HuffmanOnly..gzip.BestCompression range.
+	 */
+	SetCompressionLevel(level int) error
+
+	/*
+	Gets number of concurrent part writers used by split and parts decompressed ahead by join, default value is 1
+	 */
+	SplitConcurrency() int
+
+	/*
+	Sets number of concurrent part writers used by split. Records are still read sequentially and land in the same parts as with a single writer.
+	Join decompresses up to n-1 next parts ahead while the current part is copied. Error of any worker aborts the operation and removes already written parts.
+	 */
+	SetSplitConcurrency(n int)
+
+	/*
+	Gets threshold of in-memory buffers before they spill to temp files, default value is DefaultSpillThreshold
+	 */
+	SpillThreshold() int64
+
+	/*
+	Sets threshold of in-memory buffers used by proto buffer writer, tail buffers, reordering windows and key sets. Spills are reported through the hooks.
+	 */
+	SetSpillThreshold(threshold int64)
+
+	/*
+	Gets maximum size of CSV field, default value is DefaultMaxCsvFieldSize
+	 */
+	MaxCsvFieldSize() int
+
+	/*
+	Sets maximum size of CSV field used by readers, split and validation. Larger quoted field returns FieldTooLargeError.
+	 */
+	SetMaxCsvFieldSize(n int)
+
+	/*
+	Gets maximum size of JSON line, default value is DefaultMaxRecordSize
+	 */
+	MaxRecordSize() int
+
+	/*
+	Sets maximum size of JSON line used by readers, zero disables the limit. Larger line returns ErrRecordTooLarge,
+	reader then skips to the next line on the next read.
+	 */
+	SetMaxRecordSize(n int)
+
+	/*
+	Gets maximum size of proto message, default value is DefaultMaxProtoMessageSize
+	 */
+	MaxProtoMessageSize() int
+
+	/*
+	Sets maximum size of proto message used by readers, split and join, zero disables the limit.
+	Larger length header returns ErrMessageTooLarge before the payload is allocated.
+	 */
+	SetMaxProtoMessageSize(n int)
+
 	/*
 	Gets JSON marshal options
 	 */
@@ -50,6 +175,130 @@ type FileService interface {
 	Sets JSON unmarshal options
 	*/
 	SetUnmarshalOptions(protojson.UnmarshalOptions)
+
+	/*
+	Gets metrics hook, nil if not set
+	*/
+	MetricsHook() MetricsHook
+
+	/*
+	Sets metrics hook
+	*/
+	SetMetricsHook(MetricsHook)
+
+	/*
+	Gets writer pool shared by partitioned outputs, split by key, shard and columnar transpose
+	*/
+	WriterPool() *WriterPool
+
+	/*
+	Sets writer pool, the same pool could be shared by services to have a process-wide budget of open handles
+	*/
+	SetWriterPool(*WriterPool)
+
+	/*
+	Gets logging hook, nil if not set
+	*/
+	LoggingHook() LoggingHook
+
+	/*
+	Sets logging hook
+	*/
+	SetLoggingHook(LoggingHook)
+
+	/*
+	Gets tracing hook, nil if not set
+	*/
+	TracingHook() TracingHook
+
+	/*
+	Sets tracing hook, nil hook adds no allocations
+	*/
+	SetTracingHook(TracingHook)
+
+	/*
+	Gets path policy, nil if not set
+	*/
+	PathPolicy() PathPolicy
+
+	/*
+	Sets path policy invoked by every path-creating function, rewritten paths are returned to callers instead of requested ones
+	*/
+	SetPathPolicy(PathPolicy)
+
+	/*
+	Gets progress function, nil if not set
+	*/
+	ProgressFn() ProgressFn
+
+	/*
+	Sets progress function of split and join operations
+	*/
+	SetProgressFn(ProgressFn)
+
+	/*
+	Gets schema resolver, nil if not set
+	*/
+	SchemaResolver() SchemaResolver
+
+	/*
+	Sets schema resolver consulted by OpenProtoFile for files with schema reference, holder descriptor mismatch returns SchemaMismatchError
+	*/
+	SetSchemaResolver(SchemaResolver)
+
+	/*
+	Gets capabilities of the implementation including registered codecs, open functions consult the same registry
+	*/
+	Capabilities() CapabilitySet
+
+	/*
+	Checks if capability is supported
+	*/
+	Supports(c Capability) bool
+
+	/*
+	Gets clock used by time-dependent features, default is RealClock
+	*/
+	Clock() Clock
+
+	/*
+	Sets clock used by time-dependent features
+	*/
+	SetClock(Clock)
+
+	/*
+	Gets random source used by sampling and temp names
+	*/
+	Rand() *rand.Rand
+
+	/*
+	Sets random source used by sampling and temp names, seeded source makes them deterministic
+	*/
+	SetRand(*rand.Rand)
+
+	/*
+	Enables in-memory cache of fully-decompressed small files keyed by path, mtime and size, used by Open*File calls.
+	Files larger than 1/16 of maxBytes are not cached, entries are evicted in LRU order and after ttl.
+	Writers of this service invalidate cache entry of the path they write. Content is kept by ReadCache.
+	*/
+	EnableReadCache(maxBytes int64, ttl time.Duration)
+
+	/*
+	Disables read cache and releases cached content.
+	*/
+	DisableReadCache()
+
+	/*
+	Removes cached content of the file if any.
+	*/
+	InvalidateReadCache(filePath string)
+
+	/*
+	Closes the service: stops watchers and background goroutines, closes writers created with TrackLifetime, removes tracked temp files,
+	drains pools and flushes metrics, all bounded by the context deadline. Strict OpenWriterViolation returns OpenWritersError instead of closing writers.
+	Constructors return ErrServiceClosed after Close, repeated Close returns nil.
+	*/
+	Close(ctx context.Context) error
 }
 
 /**
@@ -63,34 +312,101 @@ type JsonFileService interface {
 	NewJsonStream(fd io.Writer, withGzip bool) JsonWriter
 
 	/*
-	Creates new JSON file in local file system. If file path ends with `.gz` extension it would be compressed.
+	Creates new JSON file in local file system. If file path ends with `.gz` or `.zst` extension it would be compressed.
 	*/
 	NewJsonFile(filePath string) (JsonWriter, error)
 
+	/*
+	Opens JSON file for append, creates it if missing. Compressed file gets a new gzip or zstd member, readers decode multistream files.
+	*/
+	AppendJsonFile(filePath string) (JsonWriter, error)
+
 	/*
 	Opens JSON stream from reader.
 	 */
 	JsonStream(fr io.Reader, withGzip bool) (JsonReader, error)
 
 	/*
-	Opens JSON file from local file system. If file path ends with `.gz` extension it would be decompressed.
+	Opens JSON file from local file system. If file path ends with `.gz` or `.zst` extension it would be decompressed.
 	*/
 	OpenJsonFile(filePath string) (JsonReader, error)
 
+	/*
+	Opens JSON file that skips malformed lines instead of returning error, skipped lines are reported by Skipped of the reader.
+	Same as OpenJsonFile with Lenient option, lines are classified by ParseJsonLine.
+	*/
+	OpenJsonFileLenient(filePath string) (JsonReader, error)
+
+	/*
+	Follows actively written JSON file, reads block on EOF and retry after poll interval until context is cancelled, then return the context error.
+	Last line is returned only after its trailing newline. Truncation or replacement of the file returns FileRotatedError.
+	*/
+	FollowJsonFile(ctx context.Context, filePath string, pollInterval time.Duration) (JsonReader, error)
+
+	/*
+	Counts records by newlines without unmarshalling, blank lines are not counted. Fresh stats sidecar is used instead of reading the file.
+	Truncated compressed file returns records counted so far with the error.
+	*/
+	CountJsonRecords(filePath string) (int64, error)
+
+	/*
+	Opens JSON file once for many concurrent readers.
+	*/
+	ShareJsonFile(filePath string) (SharedJsonSource, error)
+
+	/*
+	Opens JSON file with single top-level array and streams its elements as records, compressed files are decompressed.
+	Array that is not terminated returns CorruptInputError instead of io.EOF.
+	*/
+	OpenJsonArrayFile(filePath string) (JsonReader, error)
+
+	/*
+	Creates JSON file with single top-level array of records, closing bracket is written by Close.
+	WithJsonIndent option pretty-prints elements, proto messages use protojson indent and other values json.MarshalIndent. ReadRaw of OpenJsonArrayFile returns elements compacted.
+	*/
+	NewJsonArrayFile(filePath string) (JsonWriter, error)
+
 	/*
 	Opens JSON file from descriptor.
 	 */
 	JsonFile(fd *os.File) (JsonReader, error)
 
+	/*
+	Opens plain JSON file and reads only records written before the open, file size at open is treated as EOF.
+	Returns UnsupportedError for gzip files.
+	 */
+	OpenJsonFileSnapshot(filePath string) (JsonReader, error)
+
 	/*
 	Splits one single JSON file in to parts. Partition function would be called to format file name for each part.
+	Malformed input line is returned as ParseError.
+	If partition function panics, already created parts are closed and removed, and CallbackPanicError is returned.
+	With split concurrency above 1 full parts are compressed and written concurrently in the same order.
 	 */
 	SplitJsonFile(inputFilePath string, limit int, partitionFn func (int) string) ([]string, error)
 
 	/*
-	Joins JSON files in to one.
+	Splits JSON file in to parts of at most maxBytes written size, compressed size for compressed parts. Record larger than maxBytes goes in to its own part.
+	 */
+	SplitJsonFileBySize(inputFilePath string, maxBytes int64, partitionFn func (int) string) ([]string, error)
+
+	/*
+	Copies JSON file applying patch to every record with ApplyJsonPatch, errors are returned as RecordError with the line number.
+	 */
+	PatchJsonFile(inputPath, outputPath string, patch []JsonPatchOp) (OperationStats, error)
+
+	/*
+	Joins JSON files in to one, parts could mix codecs. If output codec equals part codec and no record-level work is requested,
+	compressed members are concatenated without recompression, MetricJoinFastPath is reported for such join. Output keeps exactly one embedded options line, parts with different options return ErrConflictingEmbeddedOptions.
 	 */
 	JoinJsonFiles(outputFilePath string, parts []string) error
+
+	/*
+	Joins JSON files keeping the first record of every key, DedupKeepLast option keeps the last one instead.
+	Only keys are kept in memory, KeepLast reads parts twice: first pass finds positions of the last records.
+	Key function error fails the join with RecordError, or skips the record with SkipKeyErrors option. Dropped records are counted in Skipped.
+	 */
+	JoinJsonFilesDedup(outputFilePath string, parts []string, keyFn func(json.RawMessage) (string, error)) (OperationStats, error)
 }
 
 /**
@@ -104,12 +420,44 @@ type JsonWriter interface {
 	WriteRaw(message json.RawMessage) error
 
 	/*
-	Writes golang object that supports serialization to JSON format.
+	Writes golang object that supports serialization to JSON format. Value implementing proto.Message is marshaled by protojson with service MarshalOptions.
+	If underlying file failed before, returns the latched error immediately.
 	 */
     Write(object interface{}) error
 
+	/*
+	Writes batch of formatted JSON messages flushing at most once. Failure returns BatchWriteError, nothing after the failed message is written.
+	 */
+	WriteRawAll(messages []json.RawMessage) error
+
+	/*
+	Writes batch of golang objects flushing at most once. Failure returns BatchWriteError, nothing after the failed object is written.
+	 */
+	WriteAll(objects []interface{}) error
+
+	/*
+	Gets writer statistics, final after Close.
+	*/
+	Stats() WriterStats
+
+	/*
+	Gets the first warnings of WarnOnly mode, total number is in stats.
+	*/
+	Warnings() []Warning
+
+	/*
+	Gets options in effect at creation of the stream.
+	*/
+	Options() OptionsSnapshot
+
+	/*
+	Flushes buffers and compressor, and syncs the file to disk, stream writers sync the writer if it implements Sync() error.
+	*/
+	Sync() error
+
     /*
-    Closes stream and flashes underline buffers
+    Closes stream and flashes underline buffers. Returns the latched write error if any, atomic writer then removes the temp file.
+    Writes and repeated Close after Close return ErrClosed.
      */
 	Close() error
 
@@ -121,17 +469,58 @@ Base interface to read content from JSON file.
 type JsonReader interface {
 
 	/*
-	Reads single row from JSON file, assuming that lines are separated by `\n` character.
+	Reads single row from JSON file, assuming that lines are separated by `\n` character. Blank lines are skipped.
+	Malformed input returns CorruptInputError, readers never panic on crafted input.
 	 */
 	ReadRaw() (json.RawMessage, error)
 
 	/*
 	Reads single raw from JSON file in to golang object. Golang object must support JSON serialization.
+	Holder implementing proto.Message is unmarshaled by protojson with service UnmarshalOptions.
+	Unmarshal error is returned as ParseError with the line and offset of the record.
+	Numbers are decoded from their literal text, so int64 and uint64 fields including nested structs and typed maps keep exact values
+	above 2^53, number that does not fit in the field returns NumberOverflowError.
 	 */
 	Read(holder interface{}) error
 
 	/*
-	Closes stream and underline buffers.
+	Iterates raw records until EOF, same as JsonRecords. Reader must be closed after the range.
+	 */
+	All() iter.Seq2[json.RawMessage, error]
+
+	/*
+	Gets number of lines consumed and uncompressed offset of the next record, could be used as checkpoint.
+	 */
+	Position() (line int64, offset int64)
+
+	/*
+	Gets the first malformed lines skipped in lenient mode with raw bytes truncated to MaxSkippedRawSize, total number is in stats.
+	 */
+	Skipped() []RecordError
+
+	/*
+	Gets options embedded in to the first line of the file, false if there were none. Options line is consumed by the reader.
+	 */
+	EmbeddedOptions() (EmbeddedOptions, bool)
+
+	/*
+	Gets reader statistics, final after Close.
+	*/
+	Stats() ReaderStats
+
+	/*
+	Gets the first warnings of WarnOnly mode, total number is in stats.
+	*/
+	Warnings() []Warning
+
+	/*
+	Gets options in effect at creation of the stream.
+	*/
+	Options() OptionsSnapshot
+
+	/*
+	Closes stream and underline buffers. Verifies compression trailer of fully consumed stream and returns ErrTrailerCorrupt on mismatch.
+	Reads and repeated Close after Close return ErrClosed.
 	 */
 	Close() error
 
@@ -149,7 +538,8 @@ type ProtoFileService interface {
 	ProtoStream(r io.Reader, withGzip bool) (ProtoReader, error)
 
 	/*
-	Opens protofile stream from load file system. If file path ends with `.gz` extension it would be decompressed.
+	Opens protofile stream from load file system. If file path ends with `.gz` or `.zst` extension it would be decompressed.
+	If file header declares message type, ReadTo returns ErrMessageTypeMismatch for holder of another type.
 	*/
 	OpenProtoFile(filePath string) (ProtoReader, error)
 
@@ -164,25 +554,95 @@ type ProtoFileService interface {
 	NewProtoStream(fd io.Writer, withGzip bool) ProtoWriter
 
 	/*
-	Creates new protofile stream. If file path ends with `.gz` extension it would be compressed.
+	Creates new protofile stream. If file path ends with `.gz` or `.zst` extension it would be compressed.
 	*/
 	NewProtoBuf(gzipEnabled bool) (ProtoWriter, error)
 
 	/*
-	Creates new protofile stream in local file system. If file path ends with `.gz` extension it would be compressed.
+	Creates new protofile stream in local file system. If file path ends with `.gz` or `.zst` extension it would be compressed.
 	 */
 	NewProtoFile(filePath string) (ProtoWriter, error)
 
+	/*
+	Opens protofile for append, creates it if missing. Existing file must end on a clean frame boundary, otherwise TruncatedTailError is returned.
+	Compressed file gets a new gzip or zstd member.
+	 */
+	AppendProtoFile(filePath string) (ProtoWriter, error)
+
+	/*
+	Follows actively written protofile, reads block on EOF and retry after poll interval until context is cancelled, then return the context error.
+	Frame is returned only when its declared length is fully written. Truncation or replacement of the file returns FileRotatedError.
+	 */
+	FollowProtoFile(ctx context.Context, filePath string, pollInterval time.Duration) (ProtoReader, error)
+
+	/*
+	Counts records reading only frame headers and discarding payloads. Fresh stats sidecar is used instead of reading the file.
+	Truncated frame returns records counted so far with CorruptInputError.
+	 */
+	CountProtoRecords(filePath string) (int64, error)
+
+	/*
+	Writes ProtoIndexSuffix sidecar with offsets of every DefaultProtoIndexStride-th record of plain protofile, compressed file returns UnsupportedError.
+	 */
+	BuildProtoIndex(filePath string) (indexPath string, err error)
+
+	/*
+	Opens plain protofile positioned at the record using index sidecar to seek close and Skip the remainder.
+	Stale index returns ErrStaleProtoIndex, missing index scans from the start.
+	 */
+	OpenProtoFileAt(filePath string, recordIndex int64) (ProtoReader, error)
+
 	/*
 	Splits one single protofile in to parts. Partition function would be called to format file name for each part.
+	Parts keep the header, frame flags and checksums of the input.
+	If partition function panics, already created parts are closed and removed, and CallbackPanicError is returned.
+	With split concurrency above 1 full parts are compressed and written concurrently in the same order.
 	*/
 	SplitProtoFile(inputFilePath string, holder proto.Message, limit int, partFn func (int) string) ([]string, error)
 
 	/*
-	Joins protofiles in to one.
+	Splits protofile in to parts of at most maxBytes written size including the header, compressed size for compressed parts. Record larger than maxBytes goes in to its own part.
+	*/
+	SplitProtoFileBySize(inputFilePath string, holder proto.Message, maxBytes int64, partFn func (int) string) ([]string, error)
+
+	/*
+	Splits protofile in to parts copying frames verbatim without unmarshalling, parts keep the header of the input.
+	Frame running past EOF returns CorruptInputError.
+	*/
+	SplitProtoFileRaw(inputFilePath string, limit int, partFn func (int) string) ([]string, error)
+
+	/*
+	Joins protofiles in to one, compressed members of parts with the same codec and header are concatenated without recompression. Parts declaring different message types return ErrMessageTypeMismatch.
+	Output keeps framing and checksums of the first part, other parts are re-framed to match.
 	*/
 	JoinProtoFiles(outputFilePath string, row proto.Message, parts []string) error
 
+	/*
+	Joins protofiles copying frames verbatim without unmarshalling, parts must have the same framing and checksums.
+	Frame running past EOF returns CorruptInputError.
+	*/
+	JoinProtoFilesRaw(outputFilePath string, parts []string) error
+
+	/*
+	Converts protofile without schema in to NDJSON file of DecodeWireMessage trees, errors carry frame indexes.
+	*/
+	DumpProtoFileDynamic(inputPath, jsonPath string) error
+
+	/*
+	Copies protofile applying field mask to every record, mask is validated against holder descriptor before the copy.
+	 */
+	ApplyFieldMaskToProtoFile(inputPath, outputPath string, holder proto.Message, mask *fieldmaskpb.FieldMask, mode MaskMode) (OperationStats, error)
+
+	/*
+	Decodes sample of frames without schema and summarizes observed field numbers and types.
+	*/
+	InspectProtoFile(filePath string, sample int) (WireReport, error)
+
+	/*
+	Unmarshals sample of records from legacy headerless file and reports unknown fields ratio as compatibility signal.
+	*/
+	SniffProtoMessageCompat(filePath string, holder proto.Message, sample int) (CompatReport, error)
+
 }
 
 /**
@@ -191,12 +651,38 @@ Base interface to write content in to proto file.
 type ProtoWriter interface {
 
 	/**
-	Writes message to the stream
+	Writes message to the stream. If underlying file failed before, returns the latched error immediately.
 	 */
 	Write(message proto.Message) ([]byte, error)
 
+	/**
+	Writes batch of messages flushing at most once. Failure returns BatchWriteError, nothing after the failed message is written.
+	 */
+	WriteAll(messages []proto.Message) error
+
+	/**
+	Writes message with frame flags, returns error if stream was not created with frame flags.
+	 */
+	WriteWithFlags(message proto.Message, flags FrameFlags) ([]byte, error)
+
+	/*
+	Gets writer statistics, final after Close.
+	*/
+	Stats() WriterStats
+
+	/*
+	Gets the first warnings of WarnOnly mode, total number is in stats.
+	*/
+	Warnings() []Warning
+
+	/*
+	Gets options in effect at creation of the stream.
+	*/
+	Options() OptionsSnapshot
+
 	/*
-	Closes stream and flashes underline buffers
+	Closes stream and flashes underline buffers. Returns the latched write error if any.
+	Writes and repeated Close after Close return ErrClosed.
 	*/
 	Close() error
 
@@ -208,12 +694,54 @@ Base interface to read content from JSON file.
 type ProtoReader interface {
 
 	/*
-	Reads size header and single protobuf object.
+	Reads size header and single protobuf object. Truncated frame returns CorruptInputError, size above the maximum returns ErrMessageTooLarge.
 	*/
 	ReadTo(message proto.Message) error
 
 	/*
-	Closes stream and underline buffers.
+	Reads size header and returns raw payload of the frame without unmarshalling, compressed record payload is decompressed.
+	*/
+	ReadRaw() ([]byte, error)
+
+	/*
+	Skips n records reading only frame headers, payloads are discarded or seeked over for plain files.
+	Early EOF returns SkipError with the number of skipped records.
+	*/
+	Skip(n int) error
+
+	/*
+	Iterates messages until EOF, same as ProtoMessages. Reader must be closed after the range.
+	*/
+	Messages(factory func() proto.Message) iter.Seq2[proto.Message, error]
+
+	/*
+	Reads single protobuf object and returns its frame flags, always zero for files without frame flags.
+	*/
+	ReadToWithFlags(message proto.Message) (FrameFlags, error)
+
+	/*
+	Gets file header, zero Version for files without header.
+	*/
+	Info() ProtoHeader
+
+	/*
+	Gets reader statistics, final after Close.
+	*/
+	Stats() ReaderStats
+
+	/*
+	Gets the first warnings of WarnOnly mode, total number is in stats.
+	*/
+	Warnings() []Warning
+
+	/*
+	Gets options in effect at creation of the stream.
+	*/
+	Options() OptionsSnapshot
+
+	/*
+	Closes stream and underline buffers. Verifies compression trailer of fully consumed stream and returns ErrTrailerCorrupt on mismatch.
+	Reads and repeated Close after Close return ErrClosed.
 	*/
 	Close() error
 
@@ -230,17 +758,29 @@ type CsvFileService interface {
 	NewCsvStream(fw io.Writer, withGzip bool, valueProcessors ...CsvValueProcessor) CsvWriter
 
 	/*
-	Creates new CSV file stream in local file system. If file path ends with `.gz` extension it would be compressed.
+	Creates new CSV file stream in local file system. If file path ends with `.gz` or `.zst` extension it would be compressed.
 	*/
 	NewCsvFile(filePath string, valueProcessors ...CsvValueProcessor) (CsvWriter, error)
 
+	/*
+	Opens CSV file for append, creates it if missing. First row written is the header, it is skipped when it equals the existing header, otherwise HeaderMismatchError is returned.
+	Compressed file gets a new gzip or zstd member.
+	*/
+	AppendCsvFile(filePath string, valueProcessors ...CsvValueProcessor) (CsvWriter, error)
+
+	/*
+	Counts rows without applying processors, quoted fields could span lines, header and types rows are not counted if hasHeader.
+	Fresh stats sidecar is used instead of reading the file. Truncated file returns rows counted so far with the error.
+	*/
+	CountCsvRecords(filePath string, hasHeader bool) (int64, error)
+
 	/*
 	Opens CSV file stream.
 	*/
 	OpenCsvStream(fr io.Reader, withGzip bool, valueProcessors ...CsvValueProcessor) (CsvStream, error)
 
 	/*
-	Opens CSV file stream from load file system. If file path ends with `.gz` extension it would be decompressed.
+	Opens CSV file stream from load file system. If file path ends with `.gz` or `.zst` extension it would be decompressed.
 	*/
 	OpenCsvFile(filePath string, valueProcessors ...CsvValueProcessor) (CsvReader, error)
 
@@ -256,11 +796,18 @@ type CsvFileService interface {
 
 	/*
 	Splits one single CSV in to parts. Partition function would be called to format file name for each part.
+	If partition function or value processor panics, already created parts are closed and removed, and CallbackPanicError is returned.
+	With split concurrency above 1 parts are compressed concurrently, each part gets its own header and processors are applied exactly once per value.
 	*/
 	SplitCsvFile(inputFilePath string, limit int, partFn func (int) string) ([]string, error)
 
 	/*
-	Joins CSV files in to one.
+	Splits CSV file in to parts of at most maxBytes written size including the header, compressed size for compressed parts. Row larger than maxBytes goes in to its own part.
+	*/
+	SplitCsvFileBySize(inputFilePath string, maxBytes int64, partFn func (int) string) ([]string, error)
+
+	/*
+	Joins CSV files in to one keeping one types row, parts with different types rows return ErrCsvTypesMismatch. With CanonicalOrder option every part is re-projected by its header on to the canonical column order.
 	*/
 	JoinCsvFiles(outputFilePath string, parts []string) error
 
@@ -268,6 +815,7 @@ type CsvFileService interface {
 
 /**
 Base interface processor that pre-process value on reading or writing, keeping certain compatibility of CSV file with other systems.
+Panic in the processor is returned from the calling Read or Write as CallbackPanicError.
  */
 type CsvValueProcessor  func(string) string
 
@@ -277,12 +825,28 @@ Base interface to write content in to CSV file.
 type CsvWriter interface {
 
 	/**
-	Writes values to the stream
+	Writes values to the stream. If underlying file failed before, returns the latched error immediately.
 	*/
 	Write(values ...string) error
 
 	/*
-	Closes stream and flashes underline buffers
+	Gets writer statistics, final after Close.
+	*/
+	Stats() WriterStats
+
+	/*
+	Gets the first warnings of WarnOnly mode, total number is in stats.
+	*/
+	Warnings() []Warning
+
+	/*
+	Gets options in effect at creation of the stream.
+	*/
+	Options() OptionsSnapshot
+
+	/*
+	Closes stream and flashes underline buffers. Returns the latched write error if any.
+	Writes and repeated Close after Close return ErrClosed.
 	*/
 	Close() error
 
@@ -295,11 +859,28 @@ type CsvStream interface {
 
 	/*
 	Reads single row from CSV file, assuming that lines are separated by `\n` character.
+	Malformed quoting returns CorruptInputError, EOF inside quoted field returns UnterminatedQuoteError.
 	*/
 	Read() ([]string, error)
 
 	/*
-	Closes stream and underline buffers.
+	Gets reader statistics, final after Close.
+	*/
+	Stats() ReaderStats
+
+	/*
+	Gets the first warnings of WarnOnly mode, total number is in stats.
+	*/
+	Warnings() []Warning
+
+	/*
+	Gets options in effect at creation of the stream.
+	*/
+	Options() OptionsSnapshot
+
+	/*
+	Closes stream and underline buffers. Verifies compression trailer of fully consumed stream and returns ErrTrailerCorrupt on mismatch.
+	Reads and repeated Close after Close return ErrClosed.
 	*/
 	Close() error
 }
@@ -311,6 +892,7 @@ type CsvReader interface {
 
 	/*
 	Reads first row from CSV file, assuming that lines are separated by `\n` character. Uses first row as a header.
+	Types row following the header is consumed and exposed by CsvFile.Types.
 	*/
 	ReadHeader() (CsvFile, error)
 
@@ -320,7 +902,28 @@ type CsvReader interface {
 	Read() ([]string, error)
 
 	/*
-	Closes stream and underline buffers.
+	Iterates rows until EOF, same as CsvRows. Reader must be closed after the range.
+	*/
+	Rows() iter.Seq2[[]string, error]
+
+	/*
+	Gets reader statistics, final after Close.
+	*/
+	Stats() ReaderStats
+
+	/*
+	Gets the first warnings of WarnOnly mode, total number is in stats.
+	*/
+	Warnings() []Warning
+
+	/*
+	Gets options in effect at creation of the stream.
+	*/
+	Options() OptionsSnapshot
+
+	/*
+	Closes stream and underline buffers. Verifies compression trailer of fully consumed stream and returns ErrTrailerCorrupt on mismatch.
+	Reads and repeated Close after Close return ErrClosed.
 	*/
 	Close() error
 }
@@ -374,6 +977,11 @@ type CsvFile interface {
 	 */
 	Index() map[string]int
 
+	/*
+	Gets column types declared by types row, nil if file has no types row.
+	 */
+	Types() []CsvType
+
 	/*
 	Reads next record. Return EOF error if no more records in file.
 	 */
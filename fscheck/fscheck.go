@@ -0,0 +1,334 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+/**
+Package fscheck asserts data contracts of produced files and absence of goroutine leaks in tests, benchmarks and fuzz targets.
+Helpers take the fs.FileService that opens the files, so codecs and options of the service apply, e.g. `fscheck.SortedBy(t, service, path, keyFn)`.
+Files are streamed through the service readers with bounded memory and the first failure location is reported through t.Errorf.
+ */
+package fscheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/sprintframework/fs"
+	"hash/fnv"
+	"io"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+/**
+Number of hash buckets used by SameRecords.
+ */
+const sameRecordsBuckets = 1024
+
+/**
+Maximum number of keys collected by SameRecords to report differing keys.
+ */
+const sameRecordsMaxKeys = 1 << 16
+
+/**
+False positive rate of the key filter used by UniqueBy.
+ */
+const uniqueFpRate = 0.001
+
+/**
+Checks that JSON file is sorted by key in string order, equal keys are allowed.
+ */
+func SortedBy(t testing.TB, service fs.FileService, path string, keyFn func(json.RawMessage) (string, error)) bool {
+	t.Helper()
+	ok := true
+	var prev string
+	first := true
+	scanJson(t, service, path, keyFn, func(line int64, key string, raw json.RawMessage) bool {
+		if !first && key < prev {
+			t.Errorf("fscheck: %s:%d: key '%s' is less than previous key '%s'", path, line, key, prev)
+			ok = false
+			return false
+		}
+		prev, first = key, false
+		return true
+	}, &ok)
+	return ok
+}
+
+/**
+Checks that every key of JSON file is unique. Keys are filtered by bloom filter, so only candidate duplicates are kept in memory.
+ */
+func UniqueBy(t testing.TB, service fs.FileService, path string, keyFn func(json.RawMessage) (string, error)) bool {
+	t.Helper()
+	n, err := service.CountJsonRecords(path)
+	if err != nil {
+		t.Errorf("fscheck: count '%s': %v", path, err)
+		return false
+	}
+	seen, err := fs.NewBloomSet(int(n), uniqueFpRate)
+	if err != nil {
+		t.Errorf("fscheck: unique '%s': %v", path, err)
+		return false
+	}
+	ok := true
+	candidates := make(map[string]int64)
+	scanJson(t, service, path, keyFn, func(line int64, key string, raw json.RawMessage) bool {
+		if seen.Contains(key) {
+			candidates[key] = 0
+		}
+		seen.Add(key)
+		return true
+	}, &ok)
+	if !ok || len(candidates) == 0 {
+		return ok
+	}
+	scanJson(t, service, path, keyFn, func(line int64, key string, raw json.RawMessage) bool {
+		firstLine, found := candidates[key]
+		if !found {
+			return true
+		}
+		if firstLine > 0 {
+			t.Errorf("fscheck: %s:%d: duplicate key '%s' first seen at line %d", path, line, key, firstLine)
+			ok = false
+			return false
+		}
+		candidates[key] = line
+		return true
+	}, &ok)
+	return ok
+}
+
+/**
+Checks that JSON files have the same records in any order. Records are compared by compacted bytes through per-bucket hashes.
+Files that differ are scanned again collecting keys of mismatched buckets, at most sameRecordsMaxKeys of them, so memory stays bounded
+when files differ broadly, and the reported first differing key is then the first among the collected ones.
+ */
+func SameRecords(t testing.TB, service fs.FileService, pathA, pathB string, keyFn func(json.RawMessage) (string, error)) bool {
+	t.Helper()
+	ok := true
+	var a, b [sameRecordsBuckets]bucket
+	for _, side := range []struct {
+		path    string
+		buckets *[sameRecordsBuckets]bucket
+	}{{pathA, &a}, {pathB, &b}} {
+		buckets := side.buckets
+		scanJson(t, service, side.path, keyFn, func(line int64, key string, raw json.RawMessage) bool {
+			i, h := recordHash(key, raw)
+			buckets[i].add(h)
+			return true
+		}, &ok)
+		if !ok {
+			return false
+		}
+	}
+	if a == b {
+		return true
+	}
+	mismatched := make(map[uint64]bool)
+	for i := range a {
+		if a[i] != b[i] {
+			mismatched[uint64(i)] = true
+		}
+	}
+	counts := make(map[string]map[uint64]int64)
+	truncated := false
+	for sign, path := range map[int64]string{1: pathA, -1: pathB} {
+		scanJson(t, service, path, keyFn, func(line int64, key string, raw json.RawMessage) bool {
+			i, h := recordHash(key, raw)
+			if !mismatched[i] {
+				return true
+			}
+			m := counts[key]
+			if m == nil {
+				if len(counts) >= sameRecordsMaxKeys {
+					truncated = true
+					return true
+				}
+				m = make(map[uint64]int64)
+				counts[key] = m
+			}
+			m[h] += sign
+			return true
+		}, &ok)
+		if !ok {
+			return false
+		}
+	}
+	var diff []string
+	for key, m := range counts {
+		for _, c := range m {
+			if c != 0 {
+				diff = append(diff, key)
+				break
+			}
+		}
+	}
+	if len(diff) == 0 {
+		t.Errorf("fscheck: '%s' and '%s' differ", pathA, pathB)
+		return false
+	}
+	first := diff[0]
+	for _, key := range diff {
+		if key < first {
+			first = key
+		}
+	}
+	if truncated {
+		t.Errorf("fscheck: '%s' and '%s' differ in records of at least %d keys, first collected key '%s'", pathA, pathB, len(diff), first)
+		return false
+	}
+	t.Errorf("fscheck: '%s' and '%s' differ in records of %d keys, first key '%s'", pathA, pathB, len(diff), first)
+	return false
+}
+
+type bucket struct {
+	count int64
+	sum   uint64
+}
+
+func (b *bucket) add(h uint64) {
+	b.count++
+	b.sum += h
+}
+
+func recordHash(key string, raw json.RawMessage) (uint64, uint64) {
+	kh := fnv.New64a()
+	kh.Write([]byte(key))
+	var compact bytes.Buffer
+	if json.Compact(&compact, raw) != nil {
+		compact.Reset()
+		compact.Write(raw)
+	}
+	rh := fnv.New64a()
+	rh.Write([]byte(key))
+	rh.Write([]byte{0})
+	rh.Write(compact.Bytes())
+	return kh.Sum64() % sameRecordsBuckets, rh.Sum64()
+}
+
+func scanJson(t testing.TB, service fs.FileService, path string, keyFn func(json.RawMessage) (string, error), fn func(line int64, key string, raw json.RawMessage) bool, ok *bool) {
+	t.Helper()
+	r, err := service.OpenJsonFile(path)
+	if err != nil {
+		t.Errorf("fscheck: open '%s': %v", path, err)
+		*ok = false
+		return
+	}
+	defer r.Close()
+	for {
+		raw, err := r.ReadRaw()
+		if err == io.EOF {
+			return
+		}
+		line, _ := r.Position()
+		if err != nil {
+			t.Errorf("fscheck: %s:%d: %v", path, line, err)
+			*ok = false
+			return
+		}
+		key, err := keyFn(raw)
+		if err != nil {
+			t.Errorf("fscheck: %s:%d: key: %v", path, line, err)
+			*ok = false
+			return
+		}
+		if !fn(line, key, raw) {
+			return
+		}
+	}
+}
+
+/**
+Expected schema of CSV file.
+ */
+type CsvSpec struct {
+
+	/*
+	Expected header in order.
+	 */
+	Header []string
+
+	/*
+	Expected column types, types row of the file is used if nil. Empty values are allowed for any type.
+	 */
+	Types []fs.CsvType
+
+	/*
+	Requires the file to have types row equal to Types.
+	 */
+	RequireTypesRow bool
+}
+
+/**
+Checks CSV header, types row and that every row has the header width and values parse by column types.
+ */
+func CsvMatchesSchema(t testing.TB, service fs.FileService, path string, spec CsvSpec) bool {
+	t.Helper()
+	r, err := service.OpenCsvFile(path)
+	if err != nil {
+		t.Errorf("fscheck: open '%s': %v", path, err)
+		return false
+	}
+	defer r.Close()
+	f, err := r.ReadHeader()
+	if err != nil {
+		t.Errorf("fscheck: %s: header: %v", path, err)
+		return false
+	}
+	header := f.Header()
+	if spec.Header != nil && !slices.Equal(header, spec.Header) {
+		t.Errorf("fscheck: %s: header %v, want %v", path, header, spec.Header)
+		return false
+	}
+	fileTypes := f.Types()
+	types := spec.Types
+	if spec.RequireTypesRow && !slices.Equal(fileTypes, types) {
+		t.Errorf("fscheck: %s: types row %v, want %v", path, fileTypes, types)
+		return false
+	}
+	if types == nil {
+		types = fileTypes
+	}
+	for row := int64(1); ; row++ {
+		values, err := r.Read()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			t.Errorf("fscheck: %s: row %d: %v", path, row, err)
+			return false
+		}
+		if len(values) != len(header) {
+			t.Errorf("fscheck: %s: row %d has %d values, header has %d", path, row, len(values), len(header))
+			return false
+		}
+		for i, value := range values {
+			if i >= len(types) || value == "" {
+				continue
+			}
+			if err := checkValue(types[i], value); err != nil {
+				t.Errorf("fscheck: %s: row %d column '%s': %v", path, row, header[i], err)
+				return false
+			}
+		}
+	}
+}
+
+func checkValue(typ fs.CsvType, value string) error {
+	var err error
+	switch typ.Kind {
+	case fs.Int64Column:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case fs.Float64Column:
+		_, err = strconv.ParseFloat(value, 64)
+	case fs.TimeColumn:
+		layout := typ.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		_, err = time.Parse(layout, value)
+	}
+	return err
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fscheck
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/sprintframework/fs"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+/**
+Service serving in-memory NDJSON and CSV files, only the methods used by the helpers are implemented.
+ */
+type memService struct {
+	fs.FileService
+	files map[string]string
+}
+
+func (s *memService) OpenJsonFile(path string) (fs.JsonReader, error) {
+	content, ok := s.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return &memJsonReader{lines: strings.Split(strings.TrimSuffix(content, "\n"), "\n")}, nil
+}
+
+func (s *memService) CountJsonRecords(path string) (int64, error) {
+	content, ok := s.files[path]
+	if !ok {
+		return 0, os.ErrNotExist
+	}
+	return int64(strings.Count(content, "\n")), nil
+}
+
+func (s *memService) OpenCsvFile(path string, valueProcessors ...fs.CsvValueProcessor) (fs.CsvReader, error) {
+	content, ok := s.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	r := csv.NewReader(strings.NewReader(content))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return &memCsvReader{rows: rows}, nil
+}
+
+type memJsonReader struct {
+	fs.JsonReader
+	lines []string
+	line  int64
+}
+
+func (r *memJsonReader) ReadRaw() (json.RawMessage, error) {
+	if r.line >= int64(len(r.lines)) {
+		return nil, io.EOF
+	}
+	r.line++
+	return json.RawMessage(r.lines[r.line-1]), nil
+}
+
+func (r *memJsonReader) Position() (int64, int64) {
+	return r.line, 0
+}
+
+func (r *memJsonReader) Close() error {
+	return nil
+}
+
+type memCsvReader struct {
+	fs.CsvReader
+	rows [][]string
+	pos  int
+}
+
+func (r *memCsvReader) ReadHeader() (fs.CsvFile, error) {
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	f := &memCsvFile{header: header}
+	if r.pos < len(r.rows) {
+		types, ok, err := fs.ParseCsvTypes(r.rows[r.pos])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			f.types = types
+			r.pos++
+		}
+	}
+	return f, nil
+}
+
+func (r *memCsvReader) Read() ([]string, error) {
+	if r.pos >= len(r.rows) {
+		return nil, io.EOF
+	}
+	r.pos++
+	return r.rows[r.pos-1], nil
+}
+
+func (r *memCsvReader) Close() error {
+	return nil
+}
+
+type memCsvFile struct {
+	fs.CsvFile
+	header []string
+	types  []fs.CsvType
+}
+
+func (f *memCsvFile) Header() []string {
+	return f.header
+}
+
+func (f *memCsvFile) Types() []fs.CsvType {
+	return f.types
+}
+
+func idKey(raw json.RawMessage) (string, error) {
+	var rec struct {
+		ID string `json:"id"`
+	}
+	err := json.Unmarshal(raw, &rec)
+	return rec.ID, err
+}
+
+func records(ids ...string) string {
+	var b strings.Builder
+	for _, id := range ids {
+		fmt.Fprintf(&b, "{\"id\":\"%s\",\"v\":1}\n", id)
+	}
+	return b.String()
+}
+
+func check(t *testing.T, want bool, fn func(tb testing.TB) bool) []string {
+	t.Helper()
+	rec := &recordingTB{TB: t}
+	if got := fn(rec); got != want || got != (len(rec.errors) == 0) {
+		t.Fatalf("result %v, want %v, errors %v", got, want, rec.errors)
+	}
+	return rec.errors
+}
+
+func TestSortedBy(t *testing.T) {
+	s := &memService{files: map[string]string{"sorted": records("a", "b", "b", "c"), "unsorted": records("a", "c", "b")}}
+	check(t, true, func(tb testing.TB) bool { return SortedBy(tb, s, "sorted", idKey) })
+	errs := check(t, false, func(tb testing.TB) bool { return SortedBy(tb, s, "unsorted", idKey) })
+	if !strings.Contains(errs[0], "unsorted:3") {
+		t.Fatalf("failure location missing: %s", errs[0])
+	}
+	check(t, false, func(tb testing.TB) bool { return SortedBy(tb, s, "missing", idKey) })
+}
+
+func TestUniqueBy(t *testing.T) {
+	s := &memService{files: map[string]string{"unique": records("a", "b", "c"), "dup": records("a", "b", "c", "b")}}
+	check(t, true, func(tb testing.TB) bool { return UniqueBy(tb, s, "unique", idKey) })
+	errs := check(t, false, func(tb testing.TB) bool { return UniqueBy(tb, s, "dup", idKey) })
+	if !strings.Contains(errs[0], "dup:4: duplicate key 'b' first seen at line 2") {
+		t.Fatalf("unexpected failure %s", errs[0])
+	}
+}
+
+func TestSameRecords(t *testing.T) {
+	s := &memService{files: map[string]string{
+		"a":       records("1", "2", "3"),
+		"b":       records("3", "1", "2"),
+		"c":       records("3", "1", "4"),
+		"compact": "{\"id\": \"3\", \"v\": 1}\n{\"id\":\"1\",\"v\":1}\n{\"id\":\"2\",\"v\":1}\n",
+	}}
+	check(t, true, func(tb testing.TB) bool { return SameRecords(tb, s, "a", "b", idKey) })
+	check(t, true, func(tb testing.TB) bool { return SameRecords(tb, s, "a", "compact", idKey) })
+	errs := check(t, false, func(tb testing.TB) bool { return SameRecords(tb, s, "a", "c", idKey) })
+	if !strings.Contains(errs[0], "2 keys, first key '2'") {
+		t.Fatalf("unexpected failure %s", errs[0])
+	}
+}
+
+func TestSameRecordsKeyCap(t *testing.T) {
+	n := sameRecordsMaxKeys + 10
+	a, b := make([]string, n), make([]string, n)
+	for i := range a {
+		a[i], b[i] = fmt.Sprint("a", i), fmt.Sprint("b", i)
+	}
+	s := &memService{files: map[string]string{"a": records(a...), "b": records(b...)}}
+	errs := check(t, false, func(tb testing.TB) bool { return SameRecords(tb, s, "a", "b", idKey) })
+	if !strings.Contains(errs[0], fmt.Sprintf("at least %d keys", sameRecordsMaxKeys)) {
+		t.Fatalf("unexpected failure %s", errs[0])
+	}
+}
+
+func TestCsvMatchesSchema(t *testing.T) {
+	s := &memService{files: map[string]string{
+		"typed":   "id,ts\n" + fs.CsvTypesPrefix + "int64,timestamp(2006-01-02)\n1,2023-01-02\n2,\n",
+		"plain":   "id,ts\n1,2023-01-02\n",
+		"badtype": "id,ts\n" + fs.CsvTypesPrefix + "int64,string\nx,y\n",
+		"width":   "id,ts\n1\n",
+	}}
+	types := []fs.CsvType{{Kind: fs.Int64Column}, {Kind: fs.TimeColumn, Layout: "2006-01-02"}}
+	check(t, true, func(tb testing.TB) bool {
+		return CsvMatchesSchema(tb, s, "typed", CsvSpec{Header: []string{"id", "ts"}, Types: types, RequireTypesRow: true})
+	})
+	check(t, true, func(tb testing.TB) bool { return CsvMatchesSchema(tb, s, "typed", CsvSpec{}) })
+	check(t, true, func(tb testing.TB) bool { return CsvMatchesSchema(tb, s, "plain", CsvSpec{Types: types}) })
+	check(t, false, func(tb testing.TB) bool { return CsvMatchesSchema(tb, s, "plain", CsvSpec{Types: types, RequireTypesRow: true}) })
+	check(t, false, func(tb testing.TB) bool { return CsvMatchesSchema(tb, s, "plain", CsvSpec{Header: []string{"ts", "id"}}) })
+	errs := check(t, false, func(tb testing.TB) bool { return CsvMatchesSchema(tb, s, "badtype", CsvSpec{}) })
+	if !strings.Contains(errs[0], "row 1 column 'id'") {
+		t.Fatalf("unexpected failure %s", errs[0])
+	}
+	check(t, false, func(tb testing.TB) bool { return CsvMatchesSchema(tb, s, "width", CsvSpec{}) })
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fscheck
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+/**
+Fails the test if goroutines started after this call are still running when the test ends.
+Leaked goroutines are given one second to exit before they are reported with their stacks.
+ */
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	before := goroutineStacks()
+	t.Cleanup(func() {
+		var leaked []string
+		deadline := time.Now().Add(time.Second)
+		for {
+			leaked = leaked[:0]
+			for id, stack := range goroutineStacks() {
+				if _, ok := before[id]; !ok && !strings.Contains(stack, "testing.tRunner") {
+					leaked = append(leaked, stack)
+				}
+			}
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		for _, stack := range leaked {
+			t.Errorf("fscheck: leaked goroutine:\n%s", stack)
+		}
+	})
+}
+
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+	stacks := make(map[string]string)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		header := string(stack)
+		if i := strings.Index(header, " ["); strings.HasPrefix(header, "goroutine ") && i > 0 {
+			stacks[header[len("goroutine "):i]] = header
+		}
+	}
+	return stacks
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fscheck
+
+import (
+	"fmt"
+	"testing"
+)
+
+type recordingTB struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (t *recordingTB) Helper() {}
+
+func (t *recordingTB) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func (t *recordingTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestVerifyNoLeaksReportsLeak(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	VerifyNoLeaks(rec)
+	stop := make(chan struct{})
+	go func() {
+		<-stop
+	}()
+	for _, fn := range rec.cleanups {
+		fn()
+	}
+	close(stop)
+	if len(rec.errors) != 1 {
+		t.Fatalf("want one leaked goroutine, got %d", len(rec.errors))
+	}
+}
+
+func TestVerifyNoLeaksClean(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	VerifyNoLeaks(rec)
+	done := make(chan struct{})
+	go func() {
+		close(done)
+	}()
+	<-done
+	for _, fn := range rec.cleanups {
+		fn()
+	}
+	if len(rec.errors) != 0 {
+		t.Fatalf("unexpected leaks %v", rec.errors)
+	}
+}
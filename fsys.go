@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"google.golang.org/protobuf/proto"
+	iofs "io/fs"
+)
+
+/**
+Base interface for reading files through io/fs.FS, e.g. embed.FS or fstest.MapFS.
+Files ending with `.gz` or `.zst` are decompressed by DetectCompression the same way as in local file system, outputs are always written to local file system.
+ */
+type FSFileService interface {
+
+	/*
+	Opens JSON file from file system.
+	 */
+	OpenJsonFileFS(fsys iofs.FS, filePath string) (JsonReader, error)
+
+	/*
+	Opens protofile from file system.
+	 */
+	OpenProtoFileFS(fsys iofs.FS, filePath string) (ProtoReader, error)
+
+	/*
+	Opens CSV file from file system.
+	 */
+	OpenCsvFileFS(fsys iofs.FS, filePath string, valueProcessors ...CsvValueProcessor) (CsvReader, error)
+
+	/*
+	Splits JSON file from file system in to local parts.
+	 */
+	SplitJsonFileFS(fsys iofs.FS, inputFilePath string, limit int, partitionFn func(int) string) ([]string, error)
+
+	/*
+	Splits protofile from file system in to local parts.
+	 */
+	SplitProtoFileFS(fsys iofs.FS, inputFilePath string, holder proto.Message, limit int, partFn func(int) string) ([]string, error)
+
+	/*
+	Splits CSV file from file system in to local parts.
+	 */
+	SplitCsvFileFS(fsys iofs.FS, inputFilePath string, limit int, partFn func(int) string) ([]string, error)
+
+	/*
+	Joins JSON parts from file system in to local file.
+	 */
+	JoinJsonFilesFS(outputFilePath string, fsys iofs.FS, parts []string) error
+
+	/*
+	Joins protofile parts from file system in to local file.
+	 */
+	JoinProtoFilesFS(outputFilePath string, row proto.Message, fsys iofs.FS, parts []string) error
+
+	/*
+	Joins CSV parts from file system in to local file.
+	 */
+	JoinCsvFilesFS(outputFilePath string, fsys iofs.FS, parts []string) error
+
+}
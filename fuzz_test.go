@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func fuzzInput(t *testing.T, data []byte, gz bool) *bufio.Reader {
+	if !gz {
+		return bufio.NewReader(bytes.NewReader(data))
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bufio.NewReader(zr)
+}
+
+func protoSeed(framing Framing, h ProtoHeader, payloads ...[]byte) []byte {
+	var out []byte
+	if h.Version > 0 {
+		header, _ := h.Marshal()
+		out = append(out, header...)
+	}
+	for _, p := range payloads {
+		out = AppendProtoFrame(out, framing, h, p, 0)
+	}
+	return out
+}
+
+func FuzzProtoFrames(f *testing.F) {
+	full := ProtoHeader{Version: ProtoHeaderVersion, MessageType: "google.protobuf.StringValue", FrameFlags: true, Checksums: true}
+	f.Add(protoSeed(LengthFraming, ProtoHeader{}, []byte{0x0a, 0x01, 'a'}, nil), false, false)
+	f.Add(protoSeed(LengthFraming, full, []byte{0x0a, 0x01, 'a'}, []byte{0x08, 0x96, 0x01}), false, true)
+	f.Add(protoSeed(VarintFraming, ProtoHeader{}, []byte{0x0a, 0x01, 'a'}), true, false)
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0x0a}, false, false)
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, true, true)
+	f.Fuzz(func(t *testing.T, data []byte, varint bool, gz bool) {
+		r := fuzzInput(t, data, gz)
+		framing := LengthFraming
+		if varint {
+			framing = VarintFraming
+		}
+		var h ProtoHeader
+		var offset int64
+		if !varint {
+			if magic, err := r.Peek(len(ProtoHeaderMagic)); err == nil && bytes.Equal(magic, ProtoHeaderMagic) {
+				r.Discard(len(ProtoHeaderMagic))
+				var err error
+				if h, err = ReadProtoHeader(r); err != nil {
+					if err != ErrInvalidProtoHeader {
+						t.Fatalf("unexpected header error %v", err)
+					}
+					return
+				}
+				header, _ := h.Marshal()
+				offset = int64(len(header))
+			}
+		}
+		frames := NewProtoFrameReader(r, framing, h, 1<<20, offset)
+		for {
+			payload, _, err := frames.Next()
+			if err == io.EOF {
+				return
+			}
+			var corrupt *CorruptInputError
+			var tooLarge *ErrMessageTooLarge
+			var mismatch *ErrChecksumMismatch
+			if errors.As(err, &corrupt) || errors.As(err, &tooLarge) || errors.As(err, &mismatch) {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected frame error %v", err)
+			}
+			DecodeWireMessage(payload)
+		}
+	})
+}
+
+func FuzzReadDelimited(f *testing.F) {
+	f.Add([]byte{0x03, 'a', 'b', 'c'}, false)
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, false)
+	f.Add([]byte{0x80}, true)
+	f.Fuzz(func(t *testing.T, data []byte, gz bool) {
+		r := fuzzInput(t, data, gz)
+		for {
+			payload, err := ReadDelimited(r, 0, 0)
+			if err != nil {
+				return
+			}
+			if len(payload) > len(data) {
+				t.Fatalf("payload of %d bytes from %d bytes of input", len(payload), len(data))
+			}
+		}
+	})
+}
+
+func FuzzJsonLines(f *testing.F) {
+	f.Add([]byte(EmbeddedOptionsPrefix+`{"useProtoNames":true}`+"\n"+`{"a":{"b":[1,2]},"a":"x"}`+"\n"), false)
+	f.Add([]byte(`{"id":"1","name":"<>"}`+"\n"+`{"id":`), true)
+	f.Add([]byte(`{"a":{"a":{"a":{"a":{}}}}}`), false)
+	f.Add([]byte(`[1,2,{"x":"y"}]`+"\n"+`"\"`), false)
+	f.Fuzz(func(t *testing.T, data []byte, gz bool) {
+		content, err := io.ReadAll(fuzzInput(t, data, gz))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range bytes.Split(content, []byte("\n")) {
+			if _, ok, _ := ParseEmbeddedOptions(line); ok {
+				continue
+			}
+			scanJsonObject(line)
+			value, found, err := jsonField(line, "a.b")
+			if peeked, peekFound, peekErr := peekJsonField(line, "a.b"); json.Valid(line) && (err == nil) == (peekErr == nil) {
+				if found != peekFound || !bytes.Equal(value, peeked) {
+					t.Fatalf("peek of %q is %q, %v, want %q, %v", line, peeked, peekFound, value, found)
+				}
+			}
+			jsonSortValue(line, "id")
+			FindDuplicateKey(line)
+			if out, err := KeepFirstKeys(line); err == nil {
+				if _, err := FindDuplicateKey(out); err != nil {
+					t.Fatalf("duplicate key left in %q: %v", out, err)
+				}
+			}
+		}
+	})
+}
+
+func FuzzCsvTypes(f *testing.F) {
+	f.Add([]byte("id,name,ts\n"+CsvTypesPrefix+"int64,string,timestamp(2006-01-02)\n1,a,2023-01-01\n"), false)
+	f.Add([]byte(CsvTypesPrefix+"\"float64\n"), true)
+	f.Add([]byte(CsvTypesPrefix+"timestamp(\n"), false)
+	f.Fuzz(func(t *testing.T, data []byte, gz bool) {
+		r := csv.NewReader(fuzzInput(t, data, gz))
+		r.FieldsPerRecord = -1
+		for {
+			row, err := r.Read()
+			if err != nil {
+				return
+			}
+			types, ok, err := ParseCsvTypes(row)
+			if ok && err == nil && len(types) != len(row) {
+				t.Fatalf("%d types for %d cells", len(types), len(row))
+			}
+		}
+	})
+}
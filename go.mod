@@ -1,6 +1,6 @@
 module github.com/sprintframework/fs
 
-go 1.17
+go 1.23
 
 require google.golang.org/protobuf v1.28.1
 
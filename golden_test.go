@@ -0,0 +1,292 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/golden files")
+
+const goldenDir = "testdata/golden"
+
+type goldenRecord struct {
+	ID    string     `json:"id"`
+	Name  string     `json:"name"`
+	Score int64      `json:"score"`
+	Flags FrameFlags `json:"-"`
+}
+
+/**
+Records of every golden file, names cover quoting, separators, non-ASCII and HTML characters.
+ */
+var goldenRecords = []goldenRecord{
+	{ID: "1", Name: "alpha", Score: 10},
+	{ID: "2", Name: "comma, \"quote\"\ttab", Score: -1},
+	{ID: "3", Name: "юникод <&>", Score: 9007199254740993},
+	{ID: "4", Name: "", Score: 0, Flags: Tombstone},
+}
+
+func goldenHeader(v FormatVariant) ProtoHeader {
+	return ProtoHeader{Version: ProtoHeaderVersion, MessageType: "google.protobuf.StringValue", FrameFlags: v.FrameFlags, Checksums: v.Checksums}
+}
+
+func goldenPayload(rec goldenRecord) ([]byte, error) {
+	return proto.MarshalOptions{Deterministic: true}.Marshal(wrapperspb.String(rec.ID + "|" + rec.Name + "|" + strconv.FormatInt(rec.Score, 10)))
+}
+
+/**
+Encodes uncompressed content of the variant, compression is applied by the caller.
+ */
+func encodeGolden(v FormatVariant) ([]byte, error) {
+	var buf bytes.Buffer
+	switch v.Format {
+	case JsonFormat:
+		if v.Header {
+			buf.Write(NewEmbeddedOptions(protojson.MarshalOptions{UseProtoNames: true}).Line())
+			buf.WriteByte('\n')
+		}
+		for _, rec := range goldenRecords {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+	case CsvFormat, TsvFormat:
+		w := csv.NewWriter(&buf)
+		if v.Format == TsvFormat {
+			w.Comma = '\t'
+		}
+		w.Write([]string{"id", "name", "score"})
+		for _, rec := range goldenRecords {
+			w.Write([]string{rec.ID, rec.Name, strconv.FormatInt(rec.Score, 10)})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+	case ProtoFormat:
+		var h ProtoHeader
+		if v.Header {
+			h = goldenHeader(v)
+			header, err := h.Marshal()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(header)
+		}
+		var out []byte
+		for _, rec := range goldenRecords {
+			payload, err := goldenPayload(rec)
+			if err != nil {
+				return nil, err
+			}
+			out = AppendProtoFrame(out, v.Framing, h, payload, rec.Flags)
+		}
+		buf.Write(out)
+	default:
+		return nil, fmt.Errorf("unsupported format %s", v.Format)
+	}
+	return buf.Bytes(), nil
+}
+
+/**
+Decodes records of uncompressed content of the variant.
+ */
+func decodeGolden(v FormatVariant, content []byte) ([]goldenRecord, error) {
+	var list []goldenRecord
+	switch v.Format {
+	case JsonFormat:
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for first := true; scanner.Scan(); first = false {
+			if first && v.Header {
+				if _, ok, err := ParseEmbeddedOptions(scanner.Bytes()); !ok {
+					return nil, fmt.Errorf("missing options line: %v", err)
+				}
+				continue
+			}
+			var rec goldenRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				return nil, err
+			}
+			list = append(list, rec)
+		}
+		return list, scanner.Err()
+	case CsvFormat, TsvFormat:
+		r := csv.NewReader(bytes.NewReader(content))
+		if v.Format == TsvFormat {
+			r.Comma = '\t'
+		}
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 || !reflect.DeepEqual(rows[0], []string{"id", "name", "score"}) {
+			return nil, fmt.Errorf("unexpected header %v", rows)
+		}
+		for _, row := range rows[1:] {
+			score, err := strconv.ParseInt(row[2], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, goldenRecord{ID: row[0], Name: row[1], Score: score})
+		}
+		return list, nil
+	case ProtoFormat:
+		r := bufio.NewReader(bytes.NewReader(content))
+		var h ProtoHeader
+		var offset int64
+		if v.Header {
+			magic := make([]byte, len(ProtoHeaderMagic))
+			if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, ProtoHeaderMagic) {
+				return nil, fmt.Errorf("missing header magic")
+			}
+			var err error
+			if h, err = ReadProtoHeader(r); err != nil {
+				return nil, err
+			}
+			if want := goldenHeader(v); h != want {
+				return nil, fmt.Errorf("header %+v, want %+v", h, want)
+			}
+			offset = int64(len(content) - r.Buffered())
+		}
+		frames := NewProtoFrameReader(r, v.Framing, h, 0, offset)
+		for {
+			payload, flags, err := frames.Next()
+			if err == io.EOF {
+				return list, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			var value wrapperspb.StringValue
+			if err := proto.Unmarshal(payload, &value); err != nil {
+				return nil, err
+			}
+			parts := strings.SplitN(value.Value, "|", 3)
+			if len(parts) != 3 {
+				return nil, fmt.Errorf("unexpected value '%s'", value.Value)
+			}
+			score, err := strconv.ParseInt(parts[2], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, goldenRecord{ID: parts[0], Name: parts[1], Score: score, Flags: flags})
+		}
+	}
+	return nil, fmt.Errorf("unsupported format %s", v.Format)
+}
+
+func expectedGolden(v FormatVariant) []goldenRecord {
+	list := make([]goldenRecord, len(goldenRecords))
+	copy(list, goldenRecords)
+	if v.Format != ProtoFormat || !v.FrameFlags {
+		for i := range list {
+			list[i].Flags = 0
+		}
+	}
+	return list
+}
+
+func gzipGolden(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/**
+Golden files are pinned bytes of every format variant, run `go test -run TestGoldenFiles -update` to rewrite them after an intended format change.
+Compressed files are compared after decompression, since compressor output is not part of the contract. Encrypted and signed containers are not variants of this package.
+ */
+func TestGoldenFiles(t *testing.T) {
+	for _, v := range FormatVariants() {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			want, err := encodeGolden(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(goldenDir, v.Name)
+			if *updateGolden {
+				data := want
+				if v.Gzip {
+					if data, err = gzipGolden(want); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := os.MkdirAll(goldenDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v, run with -update to generate", err)
+			}
+			if v.Gzip {
+				zr, err := gzip.NewReader(bytes.NewReader(data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if data, err = io.ReadAll(zr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("content of %s changed, run with -update if the format change is intended", path)
+			}
+			got, err := decodeGolden(v, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := expectedGolden(v); !reflect.DeepEqual(got, expected) {
+				t.Errorf("records of %s are %+v, want %+v", path, got, expected)
+			}
+		})
+	}
+}
+
+func TestGoldenFilesComplete(t *testing.T) {
+	entries, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := make(map[string]bool)
+	for _, v := range FormatVariants() {
+		known[v.Name] = true
+	}
+	for _, e := range entries {
+		if !known[e.Name()] {
+			t.Errorf("golden file %s has no format variant", e.Name())
+		}
+	}
+}
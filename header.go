@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+/**
+Magic prefix of protofile with header. As a legacy uint32 size header it would be larger than any supported message, so headerless files are never confused with it.
+ */
+var ProtoHeaderMagic = []byte{0xFF, 'F', 'S', 'P'}
+
+/**
+Current version of the protofile header.
+ */
+const ProtoHeaderVersion = 1
+
+/**
+Maximum size of the encoded header.
+ */
+const maxProtoHeaderSize = 64 * 1024
+
+/**
+Returned when protofile header is malformed.
+ */
+var ErrInvalidProtoHeader = errors.New("fs: invalid protofile header")
+
+/**
+Header of protofile, written as magic, version byte, uint32 BigEndian size and JSON content.
+Files without header have zero Version.
+ */
+type ProtoHeader struct {
+
+	/*
+	Header version, zero for legacy files without header.
+	 */
+	Version int `json:"-"`
+
+	/*
+	Fully-qualified name of the message type of every record.
+	 */
+	MessageType string `json:"messageType,omitempty"`
+
+	/*
+	Every frame has flags byte after the size header, size counts payload only.
+	 */
+	FrameFlags bool `json:"frameFlags,omitempty"`
+
+	/*
+	ID of the compression dictionary, zero if not used.
+	 */
+	DictionaryID uint32 `json:"dictionaryId,omitempty"`
+
+	/*
+	Reference to the schema of records in the registry, nil if not set.
+	 */
+	SchemaRef *SchemaRef `json:"schemaRef,omitempty"`
+
+	/*
+	Every frame has BigEndian CRC32C of the payload after the size header and before flags byte, so frame is `size | crc | [flags] | payload`.
+	 */
+	Checksums bool `json:"checksums,omitempty"`
+}
+
+/**
+FrameFlags is the per-record flags byte of protofile with header.
+ */
+type FrameFlags byte
+
+const (
+	/*
+	Payload is individually compressed with the codec of the file, or gzip for plain files.
+	 */
+	RecordCompressed FrameFlags = 1 << iota
+
+	/*
+	Record is a tombstone.
+	 */
+	Tombstone
+)
+
+/*
+Encodes header with magic prefix.
+ */
+func (h ProtoHeader) Marshal() ([]byte, error) {
+	content, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Write(ProtoHeaderMagic)
+	buf.WriteByte(ProtoHeaderVersion)
+	binary.Write(&buf, binary.BigEndian, uint32(len(content)))
+	buf.Write(content)
+	return buf.Bytes(), nil
+}
+
+/**
+Reads header after the magic prefix was consumed by the caller.
+ */
+func ReadProtoHeader(r io.Reader) (ProtoHeader, error) {
+	var h ProtoHeader
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return h, ErrInvalidProtoHeader
+	}
+	version, size := int(prefix[0]), binary.BigEndian.Uint32(prefix[1:])
+	if version < 1 || size > maxProtoHeaderSize {
+		return h, ErrInvalidProtoHeader
+	}
+	content := make([]byte, size)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return h, ErrInvalidProtoHeader
+	}
+	if err := json.Unmarshal(content, &h); err != nil {
+		return h, ErrInvalidProtoHeader
+	}
+	h.Version = version
+	return h, nil
+}
+
+/**
+ErrMessageTypeMismatch is returned when holder or joined part message type differs from the type declared in the header.
+ */
+type ErrMessageTypeMismatch struct {
+	Want string
+	Got  string
+}
+
+func (e *ErrMessageTypeMismatch) Error() string {
+	return fmt.Sprintf("fs: message type mismatch, want '%s', got '%s'", e.Want, e.Got)
+}
+
+/**
+Compatibility signal of the sampled legacy protofile.
+ */
+type CompatReport struct {
+
+	/*
+	Number of sampled records.
+	 */
+	Sampled int
+
+	/*
+	Number of records that failed to unmarshal.
+	 */
+	Failed int
+
+	/*
+	Total bytes of sampled records.
+	 */
+	Bytes int64
+
+	/*
+	Bytes of unknown fields in sampled records.
+	 */
+	UnknownBytes int64
+
+	/*
+	Ratio of unknown field bytes to total bytes, high value means holder is likely incompatible.
+	 */
+	UnknownRatio float64
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+/**
+Computes CRC32C checksum of the record payload.
+ */
+func RecordChecksum(payload []byte) uint32 {
+	return crc32.Checksum(payload, castagnoli)
+}
+
+/**
+ErrChecksumMismatch is returned by proto readers when record payload does not match its checksum, index is 0-based record number.
+ */
+type ErrChecksumMismatch struct {
+	Index int64
+	Want  uint32
+	Got   uint32
+}
+
+func (e *ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("fs: checksum mismatch of record %d, want %08x, got %08x", e.Index, e.Want, e.Got)
+}
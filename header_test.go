@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProtoHeaderRoundTrip(t *testing.T) {
+	want := ProtoHeader{MessageType: "google.protobuf.StringValue", FrameFlags: true, DictionaryID: 7, Checksums: true}
+	data, err := want.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, ProtoHeaderMagic) {
+		t.Fatal("header without magic")
+	}
+	got, err := ReadProtoHeader(bytes.NewReader(data[len(ProtoHeaderMagic):]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Version = ProtoHeaderVersion
+	if got.MessageType != want.MessageType || got.FrameFlags != want.FrameFlags || got.DictionaryID != want.DictionaryID || got.Checksums != want.Checksums || got.Version != want.Version {
+		t.Fatalf("read %+v, want %+v", got, want)
+	}
+}
+
+func TestReadProtoHeaderInvalid(t *testing.T) {
+	valid, _ := ProtoHeader{MessageType: "x"}.Marshal()
+	valid = valid[len(ProtoHeaderMagic):]
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"version 0": append([]byte{0}, valid[1:]...),
+		"oversize":  {1, 0xff, 0xff, 0xff, 0xff},
+		"truncated": valid[:len(valid)-1],
+		"not json":  {1, 0, 0, 0, 1, '{'},
+	} {
+		if _, err := ReadProtoHeader(bytes.NewReader(data)); err != ErrInvalidProtoHeader {
+			t.Errorf("%s: want ErrInvalidProtoHeader, got %v", name, err)
+		}
+	}
+}
+
+func TestRecordChecksum(t *testing.T) {
+	if got := RecordChecksum([]byte("123456789")); got != 0xe3069283 {
+		t.Fatalf("CRC32C check value %08x", got)
+	}
+}
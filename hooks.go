@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+Metric names reported through MetricsHook.
+ */
+const (
+	MetricReadCacheHit       = "fs.read_cache.hit"
+	MetricReadCacheMiss      = "fs.read_cache.miss"
+	MetricReadCacheEviction  = "fs.read_cache.eviction"
+	MetricReadCacheBytes     = "fs.read_cache.bytes"
+	MetricWriterReopen       = "fs.writer.reopen"
+	MetricSpill              = "fs.spill"
+	MetricSpillBytes         = "fs.spill.bytes"
+	MetricLateRecords        = "fs.merge.late_records"
+	MetricJoinFastPath       = "fs.join.fast_path"
+	MetricJoinSlowPath       = "fs.join.slow_path"
+	MetricWriterPoolEviction = "fs.writer_pool.eviction"
+	MetricWriterPoolReopen   = "fs.writer_pool.reopen"
+	MetricWriterPoolOpen     = "fs.writer_pool.open"
+)
+
+/**
+MetricsHook receives service metrics, implementation must be safe for concurrent use and cheap.
+ */
+type MetricsHook interface {
+
+	/*
+	Adds delta to the named counter.
+	 */
+	IncCounter(name string, delta int64)
+
+	/*
+	Sets current value of the named gauge.
+	 */
+	SetGauge(name string, value int64)
+
+}
+
+/**
+LoggingHook receives service events, implementation must be safe for concurrent use.
+ */
+type LoggingHook interface {
+
+	/*
+	Logs event of the operation on the file.
+	 */
+	Event(op string, filePath string, message string)
+
+}
+
+/**
+Span attribute names passed to TracingHook.
+ */
+const (
+	SpanAttrPath    = "fs.path"
+	SpanAttrFormat  = "fs.format"
+	SpanAttrCodec   = "fs.codec"
+	SpanAttrRecords = "fs.records"
+)
+
+/**
+TracingHook starts spans around open and close of every file and around bulk operations, implementation must be safe for concurrent use.
+Records attribute is known only at the end, so it is set in attrs map before the end function is called.
+ */
+type TracingHook interface {
+
+	/*
+	Starts span of the operation, returned function ends the span with the final error. Attrs map is owned by the hook.
+	 */
+	StartSpan(op string, attrs map[string]string) func(err error)
+
+}
+
+/**
+TracingHook that does nothing.
+ */
+type NoopTracingHook struct{}
+
+func (NoopTracingHook) StartSpan(op string, attrs map[string]string) func(err error) {
+	return noopSpanEnd
+}
+
+func noopSpanEnd(err error) {}
+
+/**
+Number of records between progress calls of split and join.
+ */
+const ProgressEveryRecords = 10000
+
+/**
+Maximum time between progress calls of split and join, measured by the service clock.
+ */
+const ProgressInterval = time.Second
+
+/**
+ProgressFn observes split and join, called every ProgressEveryRecords records or ProgressInterval whichever comes first, and once at the end.
+Join additionally calls it after every completed part, partsWritten is the number of completed parts of join or written parts of split.
+Progress function runs synchronously in the operation goroutine and must be cheap.
+ */
+type ProgressFn func(op string, recordsProcessed int64, bytesRead int64, partsWritten int)
+
+/**
+PathPolicy vetoes or rewrites the path before any file is created by the service, including split parts, join outputs and temp files.
+ */
+type PathPolicy func(op string, path string) (string, error)
+
+/**
+PathPolicyError is returned when path policy rejects the requested path.
+ */
+type PathPolicyError struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *PathPolicyError) Error() string {
+	return fmt.Sprintf("fs: path policy rejected '%s' in %s: %v", e.Path, e.Op, e.Err)
+}
+
+func (e *PathPolicyError) Unwrap() error {
+	return e.Err
+}
+
+/**
+Applies policy once to the requested path, rewritten path is never re-fed to the policy.
+ */
+func ApplyPathPolicy(policy PathPolicy, op string, path string) (string, error) {
+	if policy == nil {
+		return path, nil
+	}
+	rewritten, err := policy(op, path)
+	if err != nil {
+		return "", &PathPolicyError{Op: op, Path: path, Err: err}
+	}
+	return rewritten, nil
+}
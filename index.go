@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+/**
+Suffix of the proto index sidecar, e.g. `data.pb.pbidx`.
+ */
+const ProtoIndexSuffix = ".pbidx"
+
+/**
+Magic prefix of the proto index sidecar.
+ */
+var ProtoIndexMagic = []byte{0xFF, 'F', 'S', 'I'}
+
+/**
+Current version of the proto index format.
+ */
+const ProtoIndexVersion = 1
+
+/**
+Default number of records between indexed offsets.
+ */
+const DefaultProtoIndexStride = 1024
+
+/**
+Returned when index sidecar is malformed or has unknown version.
+ */
+var ErrInvalidProtoIndex = errors.New("fs: invalid proto index")
+
+/**
+Returned when index sidecar does not match size or modification time of the indexed file.
+ */
+var ErrStaleProtoIndex = errors.New("fs: proto index is stale")
+
+/**
+ProtoIndex keeps byte offset of every Stride-th record, offsets[i] is the offset of the frame of record i*Stride.
+Encoded as magic, version byte and varints: stride, file size, mtime in unix nanoseconds, number of offsets and delta-encoded offsets.
+ */
+type ProtoIndex struct {
+	Stride      int64
+	FileSize    int64
+	FileModTime time.Time
+	Offsets     []int64
+}
+
+/*
+Encodes index.
+ */
+func (x *ProtoIndex) Marshal() []byte {
+	var buf bytes.Buffer
+	buf.Write(ProtoIndexMagic)
+	buf.WriteByte(ProtoIndexVersion)
+	tmp := make([]byte, 0, binary.MaxVarintLen64)
+	put := func(v int64) {
+		buf.Write(binary.AppendVarint(tmp[:0], v))
+	}
+	put(x.Stride)
+	put(x.FileSize)
+	put(x.FileModTime.UnixNano())
+	put(int64(len(x.Offsets)))
+	prev := int64(0)
+	for _, offset := range x.Offsets {
+		put(offset - prev)
+		prev = offset
+	}
+	return buf.Bytes()
+}
+
+/*
+Checks that index matches the file, returns ErrStaleProtoIndex otherwise.
+ */
+func (x *ProtoIndex) Check(fi os.FileInfo) error {
+	if fi.Size() != x.FileSize || !fi.ModTime().Equal(x.FileModTime) {
+		return ErrStaleProtoIndex
+	}
+	return nil
+}
+
+/*
+Gets record number and offset of the closest indexed record at or before the record.
+ */
+func (x *ProtoIndex) Locate(recordIndex int64) (int64, int64) {
+	if len(x.Offsets) == 0 || x.Stride <= 0 || recordIndex < 0 {
+		return 0, 0
+	}
+	i := recordIndex / x.Stride
+	if i >= int64(len(x.Offsets)) {
+		i = int64(len(x.Offsets)) - 1
+	}
+	return i * x.Stride, x.Offsets[i]
+}
+
+/**
+Number of offsets allocated up front by ReadProtoIndex, the rest grows with the decoded data, so a corrupt count never allocates more than the sidecar holds.
+ */
+const indexChunk = 4096
+
+/**
+Reads and decodes index, offsets past the file size are rejected.
+ */
+func ReadProtoIndex(r io.Reader) (*ProtoIndex, error) {
+	br := bufio.NewReader(r)
+	prefix := make([]byte, len(ProtoIndexMagic)+1)
+	if _, err := io.ReadFull(br, prefix); err != nil || !bytes.Equal(prefix[:len(ProtoIndexMagic)], ProtoIndexMagic) || prefix[len(ProtoIndexMagic)] != ProtoIndexVersion {
+		return nil, ErrInvalidProtoIndex
+	}
+	var fields [4]int64
+	for i := range fields {
+		v, err := binary.ReadVarint(br)
+		if err != nil {
+			return nil, ErrInvalidProtoIndex
+		}
+		fields[i] = v
+	}
+	n := fields[3]
+	if n < 0 || fields[0] <= 0 || n > fields[1]+1 {
+		return nil, ErrInvalidProtoIndex
+	}
+	x := &ProtoIndex{Stride: fields[0], FileSize: fields[1], FileModTime: time.Unix(0, fields[2]), Offsets: make([]int64, 0, min(n, indexChunk))}
+	prev := int64(0)
+	for i := int64(0); i < n; i++ {
+		delta, err := binary.ReadVarint(br)
+		if err != nil || delta < 0 || delta > x.FileSize-prev {
+			return nil, ErrInvalidProtoIndex
+		}
+		prev += delta
+		x.Offsets = append(x.Offsets, prev)
+	}
+	return x, nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func indexedBody(records int, stride int64) ([]byte, *ProtoIndex) {
+	var body []byte
+	x := &ProtoIndex{Stride: stride, FileModTime: time.Unix(0, 1700000000123456789)}
+	for i := 0; i < records; i++ {
+		if int64(i)%stride == 0 {
+			x.Offsets = append(x.Offsets, int64(len(body)))
+		}
+		body = AppendProtoFrame(body, LengthFraming, ProtoHeader{}, []byte("record-"+strconv.Itoa(i)), 0)
+	}
+	x.FileSize = int64(len(body))
+	return body, x
+}
+
+func readAt(t *testing.T, body []byte, x *ProtoIndex, record int64) string {
+	t.Helper()
+	first, offset := x.Locate(record)
+	frames := NewProtoFrameReader(bufio.NewReader(bytes.NewReader(body[offset:])), LengthFraming, ProtoHeader{}, 0, offset)
+	for i := first; ; i++ {
+		payload, _, err := frames.Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", record, err)
+		}
+		if i == record {
+			return string(payload)
+		}
+	}
+}
+
+func TestProtoIndexSeek(t *testing.T) {
+	body, built := indexedBody(10, 3)
+	x, err := ReadProtoIndex(bytes.NewReader(built.Marshal()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Stride != 3 || x.FileSize != built.FileSize || !x.FileModTime.Equal(built.FileModTime) || len(x.Offsets) != 4 {
+		t.Fatalf("decoded %+v, want %+v", x, built)
+	}
+	for _, record := range []int64{0, 4, 5, 6, 9} {
+		if got, want := readAt(t, body, x, record), "record-"+strconv.FormatInt(record, 10); got != want {
+			t.Errorf("record %d is %s", record, got)
+		}
+	}
+	if first, offset := x.Locate(100); first != 9 || offset != x.Offsets[3] {
+		t.Errorf("past the end located at %d, %d", first, offset)
+	}
+	if first, offset := x.Locate(-1); first != 0 || offset != 0 {
+		t.Errorf("negative record located at %d, %d", first, offset)
+	}
+}
+
+func TestProtoIndexEmpty(t *testing.T) {
+	x, err := ReadProtoIndex(bytes.NewReader((&ProtoIndex{Stride: DefaultProtoIndexStride}).Marshal()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, offset := x.Locate(5); first != 0 || offset != 0 {
+		t.Errorf("empty index located at %d, %d", first, offset)
+	}
+}
+
+func TestProtoIndexCorruptCount(t *testing.T) {
+	data := append([]byte(nil), ProtoIndexMagic...)
+	data = append(data, ProtoIndexVersion)
+	for _, v := range []int64{1, 1 << 40, 0, 1 << 40} {
+		data = binary.AppendVarint(data, v)
+	}
+	data = binary.AppendVarint(data, 0)
+	if _, err := ReadProtoIndex(bytes.NewReader(data)); err != ErrInvalidProtoIndex {
+		t.Fatalf("want ErrInvalidProtoIndex, got %v", err)
+	}
+	_, x := indexedBody(4, 1)
+	x.Offsets[3] = x.FileSize + 1
+	if _, err := ReadProtoIndex(bytes.NewReader(x.Marshal())); err != ErrInvalidProtoIndex {
+		t.Fatalf("offset past file size accepted: %v", err)
+	}
+}
+
+func TestProtoIndexCheck(t *testing.T) {
+	_, x := indexedBody(2, 1)
+	path := t.TempDir() + "/data.pb"
+	if err := os.WriteFile(path, make([]byte, x.FileSize), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, x.FileModTime, x.FileModTime); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Check(fi); err != nil {
+		t.Fatal(err)
+	}
+	x.FileSize++
+	if err := x.Check(fi); err != ErrStaleProtoIndex {
+		t.Fatalf("want ErrStaleProtoIndex, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "fmt"
+
+/**
+Result of the file integrity verification.
+ */
+type IntegrityReport struct {
+
+	/*
+	Number of gzip members, zero for plain files.
+	 */
+	Members int
+
+	/*
+	Size of the file on disk.
+	 */
+	CompressedBytes int64
+
+	/*
+	Size of the decompressed content.
+	 */
+	UncompressedBytes int64
+
+	/*
+	Number of proto frames or JSON lines, -1 if not available cheaply.
+	 */
+	Records int64
+}
+
+/**
+IntegrityError is returned when verification finds corruption, offset is in the file bytes for gzip trailers and in uncompressed bytes for frames.
+ */
+type IntegrityError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("fs: integrity check failed at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *IntegrityError) Unwrap() error {
+	return e.Err
+}
+
+/**
+Base interface for read-only integrity verification without parsing records.
+ */
+type IntegrityService interface {
+
+	/*
+	Decompresses file to io.Discard validating CRC and length trailer of every gzip member.
+	For proto files walks length prefixes and verifies that the last frame ends exactly at EOF.
+	 */
+	VerifyFileIntegrity(filePath string) (IntegrityReport, error)
+
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/**
+StickyWriter latches the first error of the underlying writer and returns it on every next Write.
+Writers put it between the file and compression/buffer layers, so disk errors like ENOSPC surface on the next record.
+ */
+type StickyWriter struct {
+	w   io.Writer
+	mu  sync.Mutex
+	err error
+}
+
+/**
+Creates new sticky writer on top of w.
+ */
+func NewStickyWriter(w io.Writer) *StickyWriter {
+	return &StickyWriter{w: w}
+}
+
+func (t *StickyWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return 0, t.err
+	}
+	n, err := t.w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		t.err = err
+	}
+	return n, err
+}
+
+/*
+Gets the latched error, nil if all writes succeeded.
+ */
+func (t *StickyWriter) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+/**
+Creates temp file `<name>.tmp.<random>` next to the target, so rename stays on the same mount.
+Random part is taken from r, services pass their Rand so seeded source makes temp names deterministic, nil r uses source seeded by the current time.
+Symlinked target is resolved, so the temp file is created next to the physical file.
+Temp file gets permissions of the existing target, or 0666 masked by umask for the new one, so commit does not change the file mode.
+ */
+func CreateAtomicTemp(filePath string, r *rand.Rand) (*os.File, error) {
+	resolved, err := ResolvePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	var fd *os.File
+	for i := 0; i < 10000; i++ {
+		name := resolved + ".tmp." + strconv.FormatUint(uint64(r.Uint32()), 10)
+		fd, err = os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := os.Stat(resolved); err == nil {
+		if err := fd.Chmod(fi.Mode().Perm()); err != nil {
+			fd.Close()
+			os.Remove(fd.Name())
+			return nil, err
+		}
+	}
+	return fd, nil
+}
+
+/**
+Syncs and closes temp file and renames it over the target, then syncs the directory so the rename survives a crash. Temp file is removed on any error before rename.
+Symlinked target is replaced, the link itself is kept.
+ */
+func CommitAtomicTemp(fd *os.File, filePath string) error {
+	err := fd.Sync()
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		filePath, err = ResolvePath(filePath)
+	}
+	if err == nil {
+		err = os.Rename(fd.Name(), filePath)
+	}
+	if err != nil {
+		os.Remove(fd.Name())
+		return err
+	}
+	return syncDir(filepath.Dir(filePath))
+}
+
+func syncDir(dir string) error {
+	fd, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = fd.Sync()
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+/**
+Maximum number of symlinks followed by ResolvePath.
+ */
+const maxSymlinks = 255
+
+/**
+Resolves symlinks of the path to the physical path. Missing file resolves through its directory, dangling link resolves to its target.
+ */
+func ResolvePath(filePath string) (string, error) {
+	for i := 0; i < maxSymlinks; i++ {
+		resolved, err := filepath.EvalSymlinks(filePath)
+		if err == nil {
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		dir, err := filepath.EvalSymlinks(filepath.Dir(filePath))
+		if err != nil {
+			return "", err
+		}
+		filePath = filepath.Join(dir, filepath.Base(filePath))
+		fi, err := os.Lstat(filePath)
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+			return filePath, nil
+		}
+		target, err := os.Readlink(filePath)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		filePath = target
+	}
+	return "", &os.PathError{Op: "resolve", Path: filePath, Err: errors.New("too many links")}
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func commitAtomic(t *testing.T, path, content string) {
+	t.Helper()
+	fd, err := CreateAtomicTemp(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fd.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := CommitAtomicTemp(fd, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAtomicTempNewFileMode(t *testing.T) {
+	dir := t.TempDir()
+	ref, err := os.Create(filepath.Join(dir, "ref"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref.Close()
+	path := filepath.Join(dir, "out.json")
+	commitAtomic(t, path, "{}\n")
+	want, _ := os.Stat(ref.Name())
+	got, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Mode() != want.Mode() {
+		t.Fatalf("mode %v, want umask-masked %v", got.Mode(), want.Mode())
+	}
+}
+
+func TestAtomicTempKeepsTargetMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	commitAtomic(t, path, "new")
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0640 {
+		t.Fatalf("mode %v, want 0640", fi.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Fatalf("content %q", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("temp file left behind: %v", entries)
+	}
+}
+
+func TestAtomicTempSymlinkKept(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.json")
+	link := filepath.Join(dir, "link.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip(err)
+	}
+	commitAtomic(t, link, "linked")
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("link replaced: %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "linked" {
+		t.Fatalf("target content %q", data)
+	}
+}
+
+func TestAtomicTempNameFromSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	names := rand.New(rand.NewSource(7))
+	taken := path + ".tmp." + strconv.FormatUint(uint64(names.Uint32()), 10)
+	if err := os.WriteFile(taken, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := CreateAtomicTemp(path, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	if want := path + ".tmp." + strconv.FormatUint(uint64(names.Uint32()), 10); fd.Name() != want {
+		t.Fatalf("temp %s, want the next name of the source %s", fd.Name(), want)
+	}
+}
+
+type failAfterWriter struct {
+	budget int
+	writes int
+}
+
+func (w *failAfterWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if len(p) <= w.budget {
+		w.budget -= len(p)
+		return len(p), nil
+	}
+	n := w.budget
+	w.budget = 0
+	return n, syscall.ENOSPC
+}
+
+func TestStickyWriterLatchesError(t *testing.T) {
+	disk := &failAfterWriter{budget: 10}
+	w := NewStickyWriter(disk)
+	if n, err := w.Write(make([]byte, 8)); n != 8 || err != nil {
+		t.Fatalf("write %d, %v", n, err)
+	}
+	if n, err := w.Write(make([]byte, 8)); n != 2 || !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("want partial write with ENOSPC, got %d, %v", n, err)
+	}
+	if n, err := w.Write(make([]byte, 1)); n != 0 || !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("want latched ENOSPC, got %d, %v", n, err)
+	}
+	if disk.writes != 2 || !errors.Is(w.Err(), syscall.ENOSPC) {
+		t.Fatalf("underlying writes %d, latched %v", disk.writes, w.Err())
+	}
+}
+
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) / 2, nil
+}
+
+func TestStickyWriterShortWrite(t *testing.T) {
+	w := NewStickyWriter(shortWriter{})
+	if _, err := w.Write([]byte("abcd")); err != io.ErrShortWrite {
+		t.Fatalf("want short write, got %v", err)
+	}
+	if w.Err() != io.ErrShortWrite {
+		t.Fatalf("latched %v", w.Err())
+	}
+}
+
+func TestStickyWriterSurfacesBeforeClose(t *testing.T) {
+	const budget, recordSize, bufferSize = 1000, 100, 256
+	sticky := NewStickyWriter(&failAfterWriter{budget: budget})
+	buf := bufio.NewWriterSize(sticky, bufferSize)
+	record := make([]byte, recordSize)
+	failed := -1
+	for i := 0; i < 1000 && failed < 0; i++ {
+		if _, err := buf.Write(record); err != nil || sticky.Err() != nil {
+			failed = i
+		}
+	}
+	if failed < 0 {
+		t.Fatal("disk full error surfaced only at close")
+	}
+	if written := (failed + 1) * recordSize; written > budget+bufferSize+recordSize {
+		t.Fatalf("error surfaced after %d bytes, budget %d", written, budget)
+	}
+	if err := buf.Flush(); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("want ENOSPC on flush, got %v", err)
+	}
+}
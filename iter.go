@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"iter"
+)
+
+/**
+Iterates raw records of JSON reader, io.EOF ends the sequence and other errors are yielded once.
+Breaking out of the range does not close the reader, Close still must be called.
+ */
+func JsonRecords(r JsonReader) iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		for {
+			raw, err := r.ReadRaw()
+			if err == io.EOF {
+				return
+			}
+			if !yield(raw, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+/**
+Iterates messages of proto reader, factory creates holder for every record.
+Breaking out of the range does not close the reader, Close still must be called.
+ */
+func ProtoMessages(r ProtoReader, factory func() proto.Message) iter.Seq2[proto.Message, error] {
+	return func(yield func(proto.Message, error) bool) {
+		for {
+			m := factory()
+			err := r.ReadTo(m)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				m = nil
+			}
+			if !yield(m, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+/**
+Iterates rows of CSV reader.
+Breaking out of the range does not close the reader, Close still must be called.
+ */
+func CsvRows(r CsvStream) iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		for {
+			row, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(row, err) || err != nil {
+				return
+			}
+		}
+	}
+}
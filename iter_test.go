@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"testing"
+)
+
+func TestJsonRecords(t *testing.T) {
+	var got []string
+	for raw, err := range JsonRecords(newMemJsonReader("{\"a\":1}\n\n{\"a\":2}\n{\"a\":3}\n")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(raw))
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[1] != `{"a":2}` {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestJsonRecordsYieldsErrorOnce(t *testing.T) {
+	r := newMemJsonReader("{}\n")
+	r.Close()
+	var errs int
+	for _, err := range JsonRecords(r) {
+		if err != ErrClosed {
+			t.Fatalf("want ErrClosed, got %v", err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Fatalf("error yielded %d times", errs)
+	}
+}
+
+func TestCsvRows(t *testing.T) {
+	r := &memCsvReader{rows: [][]string{{"id"}, {"1"}, {"2"}}}
+	var n int
+	for row, err := range CsvRows(r) {
+		if err != nil || len(row) != 1 {
+			t.Fatalf("row %v, %v", row, err)
+		}
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("%d rows", n)
+	}
+}
+
+type sliceProtoReader struct {
+	ProtoReader
+	values []string
+	err    error
+}
+
+func (r *sliceProtoReader) ReadTo(message proto.Message) error {
+	if len(r.values) == 0 {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	message.(*wrapperspb.StringValue).Value = r.values[0]
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestProtoMessages(t *testing.T) {
+	failure := errors.New("corrupt frame")
+	r := &sliceProtoReader{values: []string{"a", "b"}, err: failure}
+	var got []string
+	var last error
+	for m, err := range ProtoMessages(r, func() proto.Message { return new(wrapperspb.StringValue) }) {
+		if err != nil {
+			if m != nil {
+				t.Fatal("message yielded with error")
+			}
+			last = err
+			continue
+		}
+		got = append(got, m.(*wrapperspb.StringValue).Value)
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" || last != failure {
+		t.Fatalf("got %v, error %v", got, last)
+	}
+}
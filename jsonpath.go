@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var errNotJsonObject = errors.New("fs: record is not a JSON object")
+
+type jsonMember struct {
+	key   string
+	lead  int
+	start int
+	end   int
+}
+
+/**
+Scans top-level members of JSON object without decoding values.
+ */
+func scanJsonObject(raw []byte) ([]jsonMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, errNotJsonObject
+	}
+	var list []jsonMember
+	for dec.More() {
+		lead := int(dec.InputOffset())
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := t.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		end := int(dec.InputOffset())
+		list = append(list, jsonMember{key: key, lead: lead, start: end - len(value), end: end})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+/**
+Gets raw value of the field by dot path, returns false if field not found.
+ */
+func jsonField(raw []byte, path string) (json.RawMessage, bool, error) {
+	key, rest, nested := cutPath(path)
+	list, err := scanJsonObject(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, m := range list {
+		if m.key == key {
+			value := raw[m.start:m.end]
+			if nested {
+				return jsonField(value, rest)
+			}
+			return value, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+/**
+Gets raw value of the field by dot path with a byte scan that stops at the field, other members are skipped without decoding.
+The record is expected to be valid JSON already, scan reports only structural errors it meets on the way.
+ */
+func peekJsonField(raw []byte, path string) (json.RawMessage, bool, error) {
+	key, rest, nested := cutPath(path)
+	i := skipJsonSpace(raw, 0)
+	if i >= len(raw) || raw[i] != '{' {
+		return nil, false, errNotJsonObject
+	}
+	i = skipJsonSpace(raw, i+1)
+	if i < len(raw) && raw[i] == '}' {
+		return nil, false, nil
+	}
+	for {
+		if i >= len(raw) || raw[i] != '"' {
+			return nil, false, errMalformedJson
+		}
+		end, err := skipJsonValue(raw, i)
+		if err != nil {
+			return nil, false, err
+		}
+		name := string(raw[i+1 : end-1])
+		if bytes.IndexByte(raw[i:end], '\\') >= 0 {
+			if err := json.Unmarshal(raw[i:end], &name); err != nil {
+				return nil, false, err
+			}
+		}
+		i = skipJsonSpace(raw, end)
+		if i >= len(raw) || raw[i] != ':' {
+			return nil, false, errMalformedJson
+		}
+		start := skipJsonSpace(raw, i+1)
+		if end, err = skipJsonValue(raw, start); err != nil {
+			return nil, false, err
+		}
+		if name == key {
+			if nested {
+				return peekJsonField(raw[start:end], rest)
+			}
+			return raw[start:end], true, nil
+		}
+		i = skipJsonSpace(raw, end)
+		switch {
+		case i < len(raw) && raw[i] == ',':
+			i = skipJsonSpace(raw, i+1)
+		case i < len(raw) && raw[i] == '}':
+			return nil, false, nil
+		default:
+			return nil, false, errMalformedJson
+		}
+	}
+}
+
+var errMalformedJson = errors.New("fs: malformed JSON record")
+
+func skipJsonSpace(raw []byte, i int) int {
+	for i < len(raw) && (raw[i] == ' ' || raw[i] == '\t' || raw[i] == '\n' || raw[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+/**
+Gets end position of the value starting at i, strings and nested containers are skipped by bytes.
+ */
+func skipJsonValue(raw []byte, i int) (int, error) {
+	if i >= len(raw) {
+		return 0, errMalformedJson
+	}
+	switch raw[i] {
+	case '"':
+		for j := i + 1; j < len(raw); j++ {
+			switch raw[j] {
+			case '\\':
+				j++
+			case '"':
+				return j + 1, nil
+			}
+		}
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(raw); j++ {
+			switch raw[j] {
+			case '"':
+				end, err := skipJsonValue(raw, j)
+				if err != nil {
+					return 0, err
+				}
+				j = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return j + 1, nil
+				}
+			}
+		}
+	default:
+		j := i
+		for j < len(raw) && bytes.IndexByte([]byte(",}] \t\r\n"), raw[j]) < 0 {
+			j++
+		}
+		if j > i {
+			return j, nil
+		}
+	}
+	return 0, errMalformedJson
+}
+
+/**
+Sets raw value of the field by dot path keeping the order and formatting of other fields.
+Missing fields and parent objects are appended to the end of the object.
+ */
+func setJsonField(raw []byte, path string, value json.RawMessage) (json.RawMessage, error) {
+	key, rest, nested := cutPath(path)
+	list, err := scanJsonObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range list {
+		if m.key == key {
+			if nested {
+				if value, err = setJsonField(raw[m.start:m.end], rest, value); err != nil {
+					return nil, err
+				}
+			}
+			return splice(raw, m.start, m.end, value), nil
+		}
+	}
+	if nested {
+		if value, err = setJsonField([]byte("{}"), rest, value); err != nil {
+			return nil, err
+		}
+	}
+	name, _ := json.Marshal(key)
+	member := append(append(name, ':'), value...)
+	pos := bytes.LastIndexByte(raw, '}')
+	if len(list) > 0 {
+		member = append([]byte{','}, member...)
+	}
+	return splice(raw, pos, pos, member), nil
+}
+
+/**
+Removes the field by dot path, returns false if field not found.
+ */
+func removeJsonField(raw []byte, path string) (json.RawMessage, bool, error) {
+	key, rest, nested := cutPath(path)
+	list, err := scanJsonObject(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	for i, m := range list {
+		if m.key != key {
+			continue
+		}
+		if nested {
+			value, found, err := removeJsonField(raw[m.start:m.end], rest)
+			if err != nil || !found {
+				return raw, found, err
+			}
+			return splice(raw, m.start, m.end, value), true, nil
+		}
+		start, end := m.lead, m.end
+		if i == 0 && len(list) > 1 {
+			end = bytes.IndexByte(raw[m.end:], ',') + m.end + 1
+			for end < len(raw) && (raw[end] == ' ' || raw[end] == '\t' || raw[end] == '\n' || raw[end] == '\r') {
+				end++
+			}
+		}
+		return splice(raw, start, end, nil), true, nil
+	}
+	return raw, false, nil
+}
+
+func splice(raw []byte, start, end int, value []byte) json.RawMessage {
+	out := make([]byte, 0, len(raw)-(end-start)+len(value))
+	out = append(out, raw[:start]...)
+	out = append(out, value...)
+	return append(out, raw[end:]...)
+}
+
+func cutPath(path string) (string, string, bool) {
+	if i := strings.IndexByte(path, '.'); i >= 0 {
+		return path[:i], path[i+1:], true
+	}
+	return path, "", false
+}
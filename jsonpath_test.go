@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+var jsonPathKeys = []string{"id", "v", "meta", "a\"b", "x y", "é"}
+
+func randomJsonValue(r *rand.Rand, depth int) interface{} {
+	switch n := r.Intn(7); {
+	case n == 0 && depth > 0:
+		return randomJsonObject(r, depth-1)
+	case n == 1 && depth > 0:
+		list := make([]interface{}, r.Intn(3))
+		for i := range list {
+			list[i] = randomJsonValue(r, depth-1)
+		}
+		return list
+	case n == 2:
+		return nil
+	case n == 3:
+		return r.Intn(2) == 0
+	case n == 4:
+		return json.Number(strconv.FormatInt(r.Int63()-r.Int63(), 10))
+	default:
+		return "s\"}," + strconv.Itoa(r.Intn(100))
+	}
+}
+
+func randomJsonObject(r *rand.Rand, depth int) map[string]interface{} {
+	obj := make(map[string]interface{})
+	for i := r.Intn(4); i > 0; i-- {
+		obj[jsonPathKeys[r.Intn(len(jsonPathKeys))]] = randomJsonValue(r, depth)
+	}
+	return obj
+}
+
+func TestPeekJsonFieldMatchesDecoder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	paths := []string{"id", "v", "a\"b", "meta.v", "meta.id", "meta.meta.v", "é"}
+	for i := 0; i < 2000; i++ {
+		raw, err := json.Marshal(randomJsonObject(r, 3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			var indented bytes.Buffer
+			json.Indent(&indented, raw, "", " \t")
+			raw = indented.Bytes()
+		}
+		for _, path := range paths {
+			want, wantOk, wantErr := jsonField(raw, path)
+			got, ok, err := peekJsonField(raw, path)
+			if (err != nil) != (wantErr != nil) || ok != wantOk || !bytes.Equal(got, want) {
+				t.Fatalf("peek %s in %s: %s %v %v, want %s %v %v", path, raw, got, ok, err, want, wantOk, wantErr)
+			}
+		}
+	}
+}
+
+func TestPeekJsonFieldMalformed(t *testing.T) {
+	for _, raw := range []string{``, `[]`, `{"a"`, `{"a":}`, `{"a":1 "b":2}`, `{"a":"1`, `{a:1}`} {
+		if _, _, err := peekJsonField([]byte(raw), "b"); err == nil {
+			t.Errorf("no error for %q", raw)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+/**
+Parses NDJSON line of the JSON reader, line is 1-based and counts blank lines.
+Blank line returns nil record and nil error in both modes. Malformed line returns RecordError with raw bytes truncated to MaxSkippedRawSize,
+strict reader returns it and lenient reader counts it in stats, keeps it in Skipped and reads the next line.
+ */
+func ParseJsonLine(file string, line int64, data []byte) (json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if json.Valid(data) {
+		return data, nil
+	}
+	err := json.Unmarshal(data, new(json.RawMessage))
+	if len(data) > MaxSkippedRawSize {
+		data = data[:MaxSkippedRawSize]
+	}
+	return nil, &RecordError{File: file, Line: line, Raw: append([]byte(nil), data...), Err: err}
+}
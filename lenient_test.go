@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+const mixedFixture = "testdata/mixed.ndjson"
+
+/*
+Reads fixture line by line the way JSON readers do, lenient mode collects skipped lines instead of stopping.
+*/
+func readMixedFixture(t *testing.T, lenient bool) ([]json.RawMessage, []RecordError, error) {
+	t.Helper()
+	fd, err := os.Open(mixedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	r := bufio.NewReader(fd)
+	var records []json.RawMessage
+	var skipped []RecordError
+	for line := int64(1); ; line++ {
+		data, readErr := r.ReadBytes('\n')
+		if len(data) > 0 {
+			record, err := ParseJsonLine(mixedFixture, line, data)
+			var recordErr *RecordError
+			switch {
+			case errors.As(err, &recordErr) && lenient:
+				skipped = append(skipped, *recordErr)
+			case err != nil:
+				return records, skipped, err
+			case record != nil:
+				records = append(records, record)
+			}
+		}
+		if readErr == io.EOF {
+			return records, skipped, nil
+		}
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+	}
+}
+
+func TestParseJsonLineStrict(t *testing.T) {
+	records, _, err := readMixedFixture(t, false)
+	var recordErr *RecordError
+	if !errors.As(err, &recordErr) || recordErr.Line != 4 || recordErr.File != mixedFixture {
+		t.Fatalf("want error at line 4, got %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("read %d records before the error", len(records))
+	}
+}
+
+func TestParseJsonLineLenient(t *testing.T) {
+	records, skipped, err := readMixedFixture(t, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, raw := range records {
+		var v struct{ ID int }
+		if err := json.Unmarshal(raw, &v); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, v.ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 3 || ids[2] != 5 {
+		t.Fatalf("read ids %v", ids)
+	}
+	lines := []int64{4, 5, 7, 9}
+	if len(skipped) != len(lines) {
+		t.Fatalf("skipped %d lines: %v", len(skipped), skipped)
+	}
+	for i, e := range skipped {
+		if e.Line != lines[i] || e.Err == nil || len(e.Raw) == 0 || len(e.Raw) > MaxSkippedRawSize {
+			t.Errorf("skipped %d: line %d, %d raw bytes, %v", i, e.Line, len(e.Raw), e.Err)
+		}
+	}
+	if !bytes.HasPrefix(skipped[1].Raw, []byte{0, 1, 0xff, 0xfe}) {
+		t.Errorf("garbage raw %q", skipped[1].Raw)
+	}
+	if len(skipped[3].Raw) != MaxSkippedRawSize {
+		t.Errorf("long line raw is %d bytes", len(skipped[3].Raw))
+	}
+}
+
+func TestParseJsonLineRawIsCopy(t *testing.T) {
+	data := []byte(`{"a":`)
+	_, err := ParseJsonLine("f", 1, data)
+	var recordErr *RecordError
+	if !errors.As(err, &recordErr) {
+		t.Fatalf("want RecordError, got %v", err)
+	}
+	data[0] = 'x'
+	if recordErr.Raw[0] != '{' {
+		t.Fatal("raw bytes share the reader buffer")
+	}
+}
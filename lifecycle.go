@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+)
+
+/**
+RunGroup owns goroutines of concurrent features. The first error or panic cancels the group context, Close cancels and waits for all goroutines.
+Every goroutine-owning type is expected to hold a group and close it in its own Close, tests prove it with fscheck.VerifyNoLeaks.
+ */
+type RunGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	err    error
+}
+
+/**
+Creates run group with context derived from parent.
+ */
+func NewRunGroup(parent context.Context) *RunGroup {
+	ctx, cancel := context.WithCancel(parent)
+	return &RunGroup{ctx: ctx, cancel: cancel}
+}
+
+/*
+Gets context of the group, cancelled on the first error or Close.
+ */
+func (g *RunGroup) Context() context.Context {
+	return g.ctx
+}
+
+/*
+Starts goroutine, panic is converted in to CallbackPanicError of the operation.
+ */
+func (g *RunGroup) Go(op string, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		var err error
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err = &CallbackPanicError{Op: op, Value: r, Stack: debug.Stack()}
+				}
+			}()
+			err = fn(g.ctx)
+		}()
+		if err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+func (g *RunGroup) fail(err error) {
+	g.mu.Lock()
+	if g.err == nil {
+		g.err = err
+	}
+	g.mu.Unlock()
+	g.cancel()
+}
+
+/*
+Waits for all goroutines and returns the first error.
+ */
+func (g *RunGroup) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+/*
+Cancels the group and waits for all goroutines, cancellation itself is not an error.
+ */
+func (g *RunGroup) Close() error {
+	g.cancel()
+	err := g.Wait()
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"github.com/sprintframework/fs"
+	"github.com/sprintframework/fs/fscheck"
+	"testing"
+)
+
+func TestRunGroupCompletion(t *testing.T) {
+	fscheck.VerifyNoLeaks(t)
+	g := fs.NewRunGroup(context.Background())
+	for i := 0; i < 8; i++ {
+		g.Go("worker", func(ctx context.Context) error {
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunGroupEarlyClose(t *testing.T) {
+	fscheck.VerifyNoLeaks(t)
+	g := fs.NewRunGroup(context.Background())
+	started := make(chan struct{})
+	g.Go("blocked", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	if err := g.Close(); err != nil {
+		t.Fatalf("cancellation reported as error: %v", err)
+	}
+}
+
+func TestRunGroupParentCancel(t *testing.T) {
+	fscheck.VerifyNoLeaks(t)
+	parent, cancel := context.WithCancel(context.Background())
+	g := fs.NewRunGroup(parent)
+	g.Go("blocked", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cancel()
+	if err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunGroupWrappedCancel(t *testing.T) {
+	g := fs.NewRunGroup(context.Background())
+	g.Go("wrapped", func(ctx context.Context) error {
+		<-ctx.Done()
+		return &fs.RecordError{Err: ctx.Err()}
+	})
+	if err := g.Close(); err != nil {
+		t.Fatalf("wrapped cancellation reported as error: %v", err)
+	}
+}
+
+func TestRunGroupFirstError(t *testing.T) {
+	fscheck.VerifyNoLeaks(t)
+	g := fs.NewRunGroup(context.Background())
+	failure := errors.New("failure")
+	g.Go("blocked", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Go("failing", func(ctx context.Context) error {
+		return failure
+	})
+	if err := g.Close(); err != failure {
+		t.Fatalf("want first error, got %v", err)
+	}
+}
+
+func TestRunGroupPanic(t *testing.T) {
+	fscheck.VerifyNoLeaks(t)
+	g := fs.NewRunGroup(context.Background())
+	g.Go("panicking", func(ctx context.Context) error {
+		panic("boom")
+	})
+	var panicErr *fs.CallbackPanicError
+	if err := g.Close(); !errors.As(err, &panicErr) || panicErr.Op != "panicking" {
+		t.Fatalf("want CallbackPanicError, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.reads++
+	return c.r.ReadAt(p, off)
+}
+
+func lookupKey(line []byte) (string, error) {
+	key, _, err := jsonSortValue(line, "k")
+	return key, err
+}
+
+/*
+Builds sorted NDJSON where line lengths cross the lookup chunk, so probes land inside, at and right after long lines.
+*/
+func sortedLookupFile(n int) ([]byte, []string) {
+	var buf bytes.Buffer
+	var keys []string
+	pads := []int{0, lookupChunkSize - 16, lookupChunkSize - 15, lookupChunkSize, 3 * lookupChunkSize, 1}
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("k%05d", i*2)
+		keys = append(keys, key)
+		copies := 1
+		if i%7 == 3 {
+			copies = 3
+		}
+		for c := 0; c < copies; c++ {
+			fmt.Fprintf(&buf, `{"k":"%s","c":%d,"pad":"%s"}`+"\n", key, c, strings.Repeat("p", pads[(i+c)%len(pads)]))
+		}
+	}
+	return buf.Bytes(), keys
+}
+
+func TestLookupSortedLinesProbeBoundaries(t *testing.T) {
+	content, keys := sortedLookupFile(60)
+	r := bytes.NewReader(content)
+	for i, key := range keys {
+		lines, err := LookupSortedLines("sorted.json", r, int64(len(content)), lookupKey, key, false)
+		if err != nil || len(lines) != 1 || !bytes.HasPrefix(lines[0], []byte(`{"k":"`+key+`","c":0,`)) {
+			t.Fatalf("first %s: %d lines, %v", key, len(lines), err)
+		}
+		all, err := LookupSortedLines("sorted.json", r, int64(len(content)), lookupKey, key, true)
+		want := 1
+		if i%7 == 3 {
+			want = 3
+		}
+		if err != nil || len(all) != want {
+			t.Fatalf("all %s: %d lines, want %d, %v", key, len(all), want, err)
+		}
+		for c, line := range all {
+			if !json.Valid(line) || !bytes.Contains(line, []byte(fmt.Sprintf(`"c":%d`, c))) {
+				t.Fatalf("all %s: line %d is %.40s", key, c, line)
+			}
+		}
+		missing := fmt.Sprintf("k%05d", i*2+1)
+		if lines, err := LookupSortedLines("sorted.json", r, int64(len(content)), lookupKey, missing, true); err != nil || len(lines) != 0 {
+			t.Fatalf("missing %s: %d lines, %v", missing, len(lines), err)
+		}
+	}
+	for _, key := range []string{"a", "z"} {
+		if lines, err := LookupSortedLines("sorted.json", r, int64(len(content)), lookupKey, key, false); err != nil || len(lines) != 0 {
+			t.Fatalf("out of range %s: %d lines, %v", key, len(lines), err)
+		}
+	}
+}
+
+func TestLookupSortedLinesSeeks(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 1<<14; i++ {
+		fmt.Fprintf(&buf, `{"k":"k%06d"}`+"\n", i)
+	}
+	r := &countingReaderAt{r: bytes.NewReader(buf.Bytes())}
+	lines, err := LookupSortedLines("short.json", r, int64(buf.Len()), lookupKey, "k012345", false)
+	if err != nil || len(lines) != 1 {
+		t.Fatalf("%d lines, %v", len(lines), err)
+	}
+	// three reads per probe: alignment, probed line and its successor
+	if r.reads > 4*15 {
+		t.Fatalf("%d reads for %d lines", r.reads, 1<<14)
+	}
+}
+
+func TestLookupSortedLinesInversion(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 100; i++ {
+		key := i
+		// the first probe lands on line 50 and compares it with line 51
+		if i == 51 {
+			key = 10
+		}
+		fmt.Fprintf(&buf, `{"k":"k%03d"}`+"\n", key)
+	}
+	_, err := LookupSortedLines("unsorted.json", bytes.NewReader(buf.Bytes()), int64(buf.Len()), lookupKey, "k049", false)
+	var unsorted *UnsortedFileError
+	if !errors.As(err, &unsorted) || unsorted.File != "unsorted.json" || unsorted.Offset != 51*int64(len(`{"k":"k000"}`+"\n")) {
+		t.Fatalf("want UnsortedFileError at line 51, got %v", err)
+	}
+}
+
+func TestLookupSortedLinesCsv(t *testing.T) {
+	content := "id,name\r\na,1\r\nb,2\r\nb,3\r\nc,4"
+	header := int64(len("id,name\r\n"))
+	r := io.NewSectionReader(strings.NewReader(content), header, int64(len(content))-header)
+	column := func(line []byte) (string, error) {
+		return strings.SplitN(string(line), ",", 2)[0], nil
+	}
+	lines, err := LookupSortedLines("sorted.csv", r, r.Size(), column, "b", true)
+	if err != nil || len(lines) != 2 || string(lines[0]) != "b,2" || string(lines[1]) != "b,3" {
+		t.Fatalf("%q, %v", lines, err)
+	}
+	if lines, _ := LookupSortedLines("sorted.csv", r, r.Size(), column, "c", false); len(lines) != 1 || string(lines[0]) != "c,4" {
+		t.Fatalf("last row without terminator: %q", lines)
+	}
+}
+
+func TestLookupSortedLinesRejectsGzip(t *testing.T) {
+	content := []byte{0x1f, 0x8b, 8, 0}
+	_, err := LookupSortedLines("sorted.json.gz", bytes.NewReader(content), int64(len(content)), lookupKey, "a", false)
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Path != "sorted.json.gz" {
+		t.Fatalf("want UnsupportedError, got %v", err)
+	}
+}
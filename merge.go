@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+	"time"
+)
+
+/**
+Result of the merge by key.
+ */
+type MergeReport struct {
+
+	/*
+	Base records replaced by overlay records.
+	 */
+	Replaced int64
+
+	/*
+	Base records without overlay.
+	 */
+	Unchanged int64
+
+	/*
+	Overlay records with new keys appended to the end.
+	 */
+	Appended int64
+
+}
+
+/**
+Base interface for upsert merge of two files by key.
+Overlay is fully loaded in memory as the hash of keys to records, use MergeSpill option to bound memory.
+Key extraction errors are returned as RecordError.
+ */
+type MergeService interface {
+
+	/*
+	Merges overlay in to base, overlay records win on key collision, output keeps base order and appends new keys at the end.
+	 */
+	MergeJsonFilesByKey(basePath, overlayPath, outputPath string, keyFn func(json.RawMessage) (string, error)) (MergeReport, error)
+
+	/*
+	Merges overlay protofile in to base, holder is used to unmarshal records for key extraction.
+	 */
+	MergeProtoFilesByKey(basePath, overlayPath, outputPath string, holder proto.Message, keyFn func(proto.Message) (string, error)) (MergeReport, error)
+
+	/*
+	Follows all inputs and writes records to output in timestamp order, buffering records within lateness window.
+	Window is flushed as the low watermark of inputs advances by the service clock. Records later than the window are counted in
+	MetricLateRecords and dropped, or written out of order with EmitLateRecords option. Buffered records are flushed on context cancel.
+	 */
+	MergeLiveJsonStreams(ctx context.Context, output JsonWriter, inputs []string, tsFn func(json.RawMessage) (time.Time, error), maxLateness time.Duration) error
+
+}
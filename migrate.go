@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"context"
+	"time"
+)
+
+/**
+Suffix of the original file kept by migration with Backup option.
+ */
+const BackupSuffix = ".bak"
+
+/**
+Name of the progress journal written by migration in to the directory, completed files are not migrated again on resume.
+ */
+const MigrateJournalName = ".fsmigrate.journal"
+
+/**
+FormatSpec is the target on-disk format of migration.
+ */
+type FormatSpec struct {
+	Format      FileFormat
+	Compression Compression
+	Header      bool
+	FrameFlags  bool
+	Framing     Framing
+	Checksums   bool
+}
+
+/*
+Checks if format variant already has the spec.
+ */
+func (s FormatSpec) Matches(v FormatVariant) bool {
+	compression := NoCompression
+	switch {
+	case v.Gzip:
+		compression = Gzip
+	case v.Zstd:
+		compression = Zstd
+	}
+	return s.Format == v.Format && s.Compression == compression && s.Header == v.Header && s.FrameFlags == v.FrameFlags && s.Framing == v.Framing && s.Checksums == v.Checksums
+}
+
+/**
+Options of the directory migration.
+ */
+type MigrateOptions struct {
+
+	/*
+	Keeps original file with BackupSuffix.
+	 */
+	Backup bool
+
+	/*
+	Number of files migrated concurrently, default is 1.
+	 */
+	Concurrency int
+
+	/*
+	Limit of bytes read per second by all workers, zero means no limit.
+	 */
+	BytesPerSecond int64
+
+	/*
+	Reports planned outcomes without writing files.
+	 */
+	DryRun bool
+
+	/*
+	Walks sub-directories. Symlinks are never followed.
+	 */
+	Recursive bool
+
+}
+
+/**
+MigrateOutcome is the result of migration of the single file.
+ */
+type MigrateOutcome int
+
+const (
+	Migrated MigrateOutcome = iota
+	AlreadyMigrated
+	MigrateFailed
+)
+
+func (o MigrateOutcome) String() string {
+	switch o {
+	case Migrated:
+		return "migrated"
+	case AlreadyMigrated:
+		return "skipped"
+	case MigrateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+/**
+Migration result of the single file.
+ */
+type MigratedFile struct {
+	Path    string
+	Outcome MigrateOutcome
+	Reason  string
+	Bytes   int64
+}
+
+/**
+Result of the directory migration.
+ */
+type MigrateReport struct {
+	Files    []MigratedFile
+	Migrated int
+	Skipped  int
+	Failed   int
+	Duration time.Duration
+}
+
+/**
+Base interface of bulk rewrite of legacy files in to the target format.
+ */
+type MigrateService interface {
+
+	/*
+	Rewrites files matching glob pattern in to the target format with atomic replacement, files already in the target format detected by header are skipped.
+	Progress is kept in MigrateJournalName, so interrupted runs resume and the directory converges to the target format.
+	Failed files do not stop migration, context cancellation returns the partial report.
+	 */
+	MigrateDirectory(ctx context.Context, dir string, pattern string, target FormatSpec, opts MigrateOptions) (MigrateReport, error)
+
+}
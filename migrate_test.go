@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestFormatSpecMatchesOneVariant(t *testing.T) {
+	variants := FormatVariants()
+	for _, v := range variants {
+		spec := FormatSpec{Format: v.Format, Header: v.Header, FrameFlags: v.FrameFlags, Framing: v.Framing, Checksums: v.Checksums}
+		switch {
+		case v.Gzip:
+			spec.Compression = Gzip
+		case v.Zstd:
+			spec.Compression = Zstd
+		}
+		var matched []string
+		for _, other := range variants {
+			if spec.Matches(other) {
+				matched = append(matched, other.Name)
+			}
+		}
+		if len(matched) != 1 || matched[0] != v.Name {
+			t.Errorf("spec of %s matches %v", v.Name, matched)
+		}
+	}
+}
+
+func TestMigrateOutcomeString(t *testing.T) {
+	for outcome, want := range map[MigrateOutcome]string{Migrated: "migrated", AlreadyMigrated: "skipped", MigrateFailed: "failed"} {
+		if outcome.String() != want {
+			t.Errorf("%d is %s, want %s", outcome, outcome, want)
+		}
+	}
+}
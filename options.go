@@ -0,0 +1,798 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"time"
+)
+
+/**
+Option modifies operation settings of the derived service created by FileService.With.
+ */
+type Option func(*Options)
+
+/**
+Options are the operation settings collected from the list of Option.
+Implementation copies them on each file opening or creation.
+ */
+type Options struct {
+
+	/*
+	Column order of CSV output used by join, nil means the header of the first part.
+	 */
+	CanonicalHeader []string
+
+	/*
+	Fail join instead of dropping columns that are not in the canonical header.
+	 */
+	FailOnExtraColumns bool
+
+	/*
+	Interval of inode check for path-based writers, zero disables the check.
+	 */
+	ReopenInterval time.Duration
+
+	/*
+	Hooks applied in order to every raw JSON record before it is written.
+	 */
+	JsonWriteHooks []JsonWriteHook
+
+	/*
+	Collects raw record size histogram in reader stats.
+	 */
+	CollectSizeStats bool
+
+	/*
+	Records larger than this size are reported to OutlierFn, zero disables the check.
+	 */
+	OutlierThreshold int64
+
+	/*
+	Called with record index and size of every record exceeding OutlierThreshold.
+	 */
+	OutlierFn func(index int64, size int64)
+
+	/*
+	Tar bundle path where split writes parts as entries, empty means loose part files.
+	 */
+	SplitTarPath string
+
+	/*
+	Number of overlay records kept in memory by merge before spilling them to temp file, zero means unbounded.
+	 */
+	MergeSpillRecords int
+
+	/*
+	JSON records matching the predicate are dropped by readers before Read and ReadRaw.
+	 */
+	JsonTombstoneFilter func(json.RawMessage) bool
+
+	/*
+	Proto records matching the predicate are skipped by ReadTo.
+	 */
+	ProtoTombstoneFilter func(proto.Message) bool
+
+	/*
+	Cells that fail to parse are stored as nulls instead of returning error.
+	 */
+	NullOnParseError bool
+
+	/*
+	Layout of time values, empty means RFC3339.
+	 */
+	TimeLayout string
+
+	/*
+	Names split part after it is complete, partition function then formats only temp part names.
+	 */
+	FinalizeName func(info PartInfo) string
+
+	/*
+	Extracts key of JSON record for the first and the last keys of the part.
+	 */
+	JsonPartKey func(json.RawMessage) (string, error)
+
+	/*
+	Drains partially consumed compressed readers on Close to verify the trailer.
+	 */
+	VerifyOnClose bool
+
+	/*
+	JSON writers emit protojson options line as the first line of the file.
+	 */
+	EmbedOptions bool
+
+	/*
+	JSON readers skip lines starting with `#`.
+	 */
+	SkipCommentLines bool
+
+	/*
+	Fingerprint includes hash of the full content.
+	 */
+	FullFingerprint bool
+
+	/*
+	Fingerprint of compressed file is computed on decompressed content.
+	 */
+	LogicalFingerprint bool
+
+	/*
+	Fully-qualified message type written in to protofile header.
+	 */
+	MessageType string
+
+	/*
+	Append writers maintain statistics sidecar on Close.
+	 */
+	StatsSidecar bool
+
+	/*
+	Extracts timestamp of JSON record for the statistics sidecar.
+	 */
+	JsonTimestamp func(json.RawMessage) (time.Time, error)
+
+	/*
+	Behavior of JSON readers for duplicate keys.
+	 */
+	DuplicateKeys DuplicateKeyMode
+
+	/*
+	Bulk operations write `<output>.report.json`.
+	 */
+	Report bool
+
+	/*
+	Proto writers emit flags byte for every frame.
+	 */
+	FrameFlags bool
+
+	/*
+	Compression dictionary of zstd codec.
+	 */
+	Dictionary *Dictionary
+
+	/*
+	Patch operations on missing paths are skipped instead of failing.
+	 */
+	SkipMissingPaths bool
+
+	/*
+	Exceeded limits produce warnings instead of errors.
+	 */
+	WarnOnly bool
+
+	/*
+	Records exceeding limits in WarnOnly mode are skipped instead of processed.
+	 */
+	SkipOnWarning bool
+
+	/*
+	Number of warnings kept, zero means DefaultMaxWarnings.
+	 */
+	MaxWarnings int
+
+	/*
+	Name of provenance field stamped by JSON bulk operations, empty disables provenance.
+	 */
+	ProvenanceField string
+
+	/*
+	Live merge writes late records out of order instead of dropping them.
+	 */
+	EmitLateRecords bool
+
+	/*
+	Writers create temp file in the same directory and rename it to the target path on successful Close.
+	 */
+	Atomic bool
+
+	/*
+	Types of CSV columns written as the types row after the header, nil disables the row.
+	 */
+	CsvTypes []CsvType
+
+	/*
+	Registers writer in the service, so service Close flushes and closes it.
+	 */
+	TrackLifetime bool
+
+	/*
+	Field mask applied by proto readers to every record after unmarshal, so proto to JSON exports are trimmed in one pass.
+	 */
+	FieldMask *fieldmaskpb.FieldMask
+
+	/*
+	Mode of the field mask.
+	 */
+	FieldMaskMode MaskMode
+
+	/*
+	Shared source Close invalidates outstanding readers instead of waiting for them.
+	 */
+	InvalidateSharedReaders bool
+
+	/*
+	Deduplicating join keeps the last record of the key.
+	 */
+	DedupKeepLast bool
+
+	/*
+	Records with key function error are skipped instead of failing the operation.
+	 */
+	SkipKeyErrors bool
+
+	/*
+	Prefix and indent of pretty-printed JSON array elements, empty indent writes compact elements.
+	 */
+	JsonIndentPrefix string
+	JsonIndent       string
+
+	/*
+	Fanout writes record to every matching route.
+	 */
+	MultiMatch bool
+
+	/*
+	Records with predicate error are skipped instead of failing the operation.
+	 */
+	SkipPredicateErrors bool
+
+	/*
+	Schema reference written in to protofile header.
+	 */
+	SchemaRef *SchemaRef
+
+	/*
+	JSON readers, split and join skip malformed lines, up to MaxWarnings of them are kept.
+	 */
+	Lenient bool
+
+	/*
+	Time since the last modification after which inbox file without marker is complete, zero means DefaultQuietPeriod.
+	 */
+	QuietPeriod time.Duration
+
+	/*
+	Paths are resolved to physical paths with ResolvePath before any operation, inputs of multi-file operations are deduplicated by resolved path.
+	 */
+	ResolveSymlinks bool
+
+	/*
+	Length prefix of proto records written and read by streams, split and join.
+	 */
+	Framing Framing
+
+	/*
+	Proto writers store CRC32C checksum of every record, readers detect checksums from the header.
+	 */
+	Checksums bool
+
+}
+
+/**
+Collects options in to the struct.
+ */
+func NewOptions(options ...Option) Options {
+	var o Options
+	for _, opt := range options {
+		opt(&o)
+	}
+	return o
+}
+
+/**
+Re-projects every CSV part on to the given column order during the join.
+Missing columns are empty, extra columns are dropped unless FailOnExtraColumns is set.
+ */
+func CanonicalOrder(header []string) Option {
+	return func(o *Options) {
+		o.CanonicalHeader = append([]string(nil), header...)
+	}
+}
+
+/**
+Fails CSV join with HeaderMismatchError if part has a column outside of the canonical header.
+ */
+func FailOnExtraColumns() Option {
+	return func(o *Options) {
+		o.FailOnExtraColumns = true
+	}
+}
+
+/**
+Path-based writers periodically and before each flush check that the path still refers to the same file.
+If file was renamed or removed by log rotation, writer finishes the current gzip member, closes the old handle and reopens the path in append mode.
+Records are never split between the old and the new file.
+ */
+func ReopenOnRotate(interval time.Duration) Option {
+	return func(o *Options) {
+		o.ReopenInterval = interval
+	}
+}
+
+/**
+Adds write hook to JSON writers, hooks are applied in order of adding.
+ */
+func WithJsonWriteHook(hook JsonWriteHook) Option {
+	return func(o *Options) {
+		o.JsonWriteHooks = append(o.JsonWriteHooks, hook)
+	}
+}
+
+/**
+Enables raw record size histogram in reader stats and bulk operations.
+ */
+func CollectSizeStats(enabled bool) Option {
+	return func(o *Options) {
+		o.CollectSizeStats = enabled
+	}
+}
+
+/**
+Calls fn for every record which raw size exceeds threshold.
+ */
+func OnOutlierRecord(threshold int64, fn func(index int64, size int64)) Option {
+	return func(o *Options) {
+		o.OutlierThreshold = threshold
+		o.OutlierFn = fn
+	}
+}
+
+/**
+OptionsSnapshot captures settings in effect when reader or writer was created, later service changes do not affect it.
+Never contains key material.
+ */
+type OptionsSnapshot struct {
+
+	/*
+	Buffer size of the stream.
+	 */
+	BufferSize int
+
+	/*
+	Stream is gzip compressed.
+	 */
+	Gzip bool
+
+	/*
+	Compression codec and level of the stream.
+	 */
+	Compression      Compression
+	CompressionLevel int
+
+	/*
+	JSON marshal options used by writers.
+	 */
+	MarshalOptions protojson.MarshalOptions
+
+	/*
+	JSON unmarshal options used by readers.
+	 */
+	UnmarshalOptions protojson.UnmarshalOptions
+
+	/*
+	Number of CSV value processors.
+	 */
+	CsvProcessors int
+
+	/*
+	Number of JSON write hooks.
+	 */
+	JsonWriteHooks int
+
+	/*
+	Reader collects size stats.
+	 */
+	CollectSizeStats bool
+
+	/*
+	Reopen interval of path-based writer.
+	 */
+	ReopenInterval time.Duration
+
+	/*
+	Writer renames temp file to the target path on Close.
+	 */
+	Atomic bool
+
+	/*
+	Framing of proto records.
+	 */
+	Framing Framing
+
+}
+
+/**
+Split streams parts as entries of tar bundle without temp files, manifest is written as the final entry.
+Partition function formats entry names, bundle is gzip compressed if path ends with `.gz` or `.tgz`.
+ */
+func SplitToTar(tarPath string) Option {
+	return func(o *Options) {
+		o.SplitTarPath = tarPath
+	}
+}
+
+/**
+Spills overlay records to temp file once merge holds more than maxRecords in memory, only keys and offsets stay in memory.
+ */
+func MergeSpill(maxRecords int) Option {
+	return func(o *Options) {
+		o.MergeSpillRecords = maxRecords
+	}
+}
+
+/**
+Drops JSON records matching the predicate, they are counted in reader stats as filtered.
+ */
+func WithTombstoneFilter(pred func(json.RawMessage) bool) Option {
+	return func(o *Options) {
+		o.JsonTombstoneFilter = pred
+	}
+}
+
+/**
+Skips proto records matching the predicate, they are counted in reader stats as filtered.
+ */
+func WithProtoTombstoneFilter(pred func(proto.Message) bool) Option {
+	return func(o *Options) {
+		o.ProtoTombstoneFilter = pred
+	}
+}
+
+/**
+Stores cells that fail to parse as nulls instead of returning CsvCellError.
+ */
+func NullOnParseError() Option {
+	return func(o *Options) {
+		o.NullOnParseError = true
+	}
+}
+
+/**
+Sets layout of time values parsed from text.
+ */
+func WithTimeLayout(layout string) Option {
+	return func(o *Options) {
+		o.TimeLayout = layout
+	}
+}
+
+/**
+Defers naming of the split part until it is complete, then renames temp part to the name returned by fn atomically.
+Parts with identical content and name are deduplicated keeping the first one, different content with the same name returns PartNameCollisionError.
+ */
+func FinalizePartName(fn func(info PartInfo) string) Option {
+	return func(o *Options) {
+		o.FinalizeName = fn
+	}
+}
+
+/**
+Sets key extractor of JSON records used to fill first and last keys of split parts.
+ */
+func WithJsonPartKey(keyFn func(json.RawMessage) (string, error)) Option {
+	return func(o *Options) {
+		o.JsonPartKey = keyFn
+	}
+}
+
+/**
+Makes Close of partially consumed compressed reader drain remaining content to io.Discard and verify the trailer.
+Fully consumed readers always verify the trailer on Close.
+ */
+func VerifyOnClose(enabled bool) Option {
+	return func(o *Options) {
+		o.VerifyOnClose = enabled
+	}
+}
+
+/**
+Makes JSON writer emit the first line with protojson options, e.g. `#fsopts {"useProtoNames":true}`.
+ */
+func WithEmbeddedOptions() Option {
+	return func(o *Options) {
+		o.EmbedOptions = true
+	}
+}
+
+/**
+Makes JSON reader skip lines starting with `#`, used to read files with embedded options by older consumers.
+ */
+func SkipCommentLines() Option {
+	return func(o *Options) {
+		o.SkipCommentLines = true
+	}
+}
+
+/**
+Makes fingerprint include hash of the full content.
+ */
+func FullFingerprint() Option {
+	return func(o *Options) {
+		o.FullFingerprint = true
+	}
+}
+
+/**
+Makes fingerprint of compressed file use decompressed content.
+ */
+func LogicalFingerprint() Option {
+	return func(o *Options) {
+		o.LogicalFingerprint = true
+	}
+}
+
+/**
+Makes proto writers emit header with fully-qualified name of the message, nil message clears the name.
+Name is resolved when the option is applied, typed nil pointer of generated message is a valid type reference.
+ */
+func WithMessageType(m proto.Message) Option {
+	return func(o *Options) {
+		o.MessageType = ""
+		if m != nil {
+			o.MessageType = string(m.ProtoReflect().Descriptor().FullName())
+		}
+	}
+}
+
+/**
+Makes append writers update `<file>.stats.json` sidecar atomically on every Close.
+ */
+func WithStatsSidecar() Option {
+	return func(o *Options) {
+		o.StatsSidecar = true
+	}
+}
+
+/**
+Sets timestamp extractor of JSON records used for first and last timestamps in statistics sidecar.
+ */
+func WithJsonTimestamp(tsFn func(json.RawMessage) (time.Time, error)) Option {
+	return func(o *Options) {
+		o.JsonTimestamp = tsFn
+	}
+}
+
+/**
+Sets behavior of JSON readers for objects with duplicate keys, records are pre-scanned only if mode is not KeepLast.
+ */
+func WithDuplicateKeyMode(mode DuplicateKeyMode) Option {
+	return func(o *Options) {
+		o.DuplicateKeys = mode
+	}
+}
+
+/**
+Makes bulk operations (split, join, sort, merge) write `<output>.report.json` report, also when operation fails.
+Failure to write the report never replaces the error of the operation.
+ */
+func WithReport() Option {
+	return func(o *Options) {
+		o.Report = true
+	}
+}
+
+/**
+Makes proto writers emit header declaring frame flags and a flags byte after the size header of every frame.
+ */
+func WithFrameFlags() Option {
+	return func(o *Options) {
+		o.FrameFlags = true
+	}
+}
+
+/**
+Makes zstd codec use the dictionary, readers return DictionaryMismatchError if file was written with another one.
+ */
+func WithDictionary(d Dictionary) Option {
+	return func(o *Options) {
+		o.Dictionary = &d
+	}
+}
+
+/**
+Makes patch skip operations on missing paths instead of returning RecordError with MissingPathError.
+ */
+func SkipMissingPaths() Option {
+	return func(o *Options) {
+		o.SkipMissingPaths = true
+	}
+}
+
+/**
+Turns limit errors (max record size, decompression limit, quota) in to warnings reported through Warnings, stats and logging hook.
+With skip the offending record is dropped, otherwise it is processed.
+ */
+func WarnOnly(enabled bool, skip bool) Option {
+	return func(o *Options) {
+		o.WarnOnly = enabled
+		o.SkipOnWarning = skip
+	}
+}
+
+/**
+Sets number of warnings kept by readers and writers, the rest is only counted.
+ */
+func MaxWarnings(n int) Option {
+	return func(o *Options) {
+		o.MaxWarnings = n
+	}
+}
+
+/**
+Makes JSON bulk operations stamp every record with StampProvenance using the input path and line, empty field means DefaultProvenanceField.
+ */
+func WithProvenance(field string) Option {
+	if field == "" {
+		field = DefaultProvenanceField
+	}
+	return func(o *Options) {
+		o.ProvenanceField = field
+	}
+}
+
+/**
+Makes live merge write records arriving later than the lateness window out of order instead of dropping them.
+ */
+func EmitLateRecords() Option {
+	return func(o *Options) {
+		o.EmitLateRecords = true
+	}
+}
+
+/**
+Makes path-based writers write to `<name>.tmp.<random>` in the target directory and rename it over the target only when Close succeeds.
+Failed writes and writers that were never closed successfully remove the temp file. Split parts are always written this way.
+ */
+func AtomicWrites() Option {
+	return func(o *Options) {
+		o.Atomic = true
+	}
+}
+
+/**
+Makes CSV writers emit types row like `#types:int64,string,timestamp(2006-01-02)` right after the header.
+ */
+func WithTypedHeader(types []CsvType) Option {
+	return func(o *Options) {
+		o.CsvTypes = append([]CsvType(nil), types...)
+	}
+}
+
+/**
+Makes service Close flush and close writers that are still open.
+ */
+func TrackLifetime() Option {
+	return func(o *Options) {
+		o.TrackLifetime = true
+	}
+}
+
+/**
+Makes proto readers apply field mask to every record, unknown paths fail on the first read with UnknownMaskPathError.
+ */
+func WithFieldMask(mask *fieldmaskpb.FieldMask, mode MaskMode) Option {
+	return func(o *Options) {
+		o.FieldMask = mask
+		o.FieldMaskMode = mode
+	}
+}
+
+/**
+Makes shared source Close invalidate outstanding readers instead of waiting for them.
+ */
+func InvalidateSharedReaders() Option {
+	return func(o *Options) {
+		o.InvalidateSharedReaders = true
+	}
+}
+
+/**
+Makes deduplicating join keep the last record of the key instead of the first one.
+ */
+func DedupKeepLast() Option {
+	return func(o *Options) {
+		o.DedupKeepLast = true
+	}
+}
+
+/**
+Makes keyed operations skip records for which key function returns error.
+ */
+func SkipKeyErrors() Option {
+	return func(o *Options) {
+		o.SkipKeyErrors = true
+	}
+}
+
+/**
+Makes JSON array writers pretty-print elements. Indent is supported only by the array format,
+NDJSON writers return UnsupportedError since readers rely on one record per line.
+ */
+func WithJsonIndent(prefix, indent string) Option {
+	return func(o *Options) {
+		o.JsonIndentPrefix = prefix
+		o.JsonIndent = indent
+	}
+}
+
+/**
+Makes fanout write record to every matching route instead of the first one.
+ */
+func MultiMatch() Option {
+	return func(o *Options) {
+		o.MultiMatch = true
+	}
+}
+
+/**
+Makes fanout skip records for which predicate returns error.
+ */
+func SkipPredicateErrors() Option {
+	return func(o *Options) {
+		o.SkipPredicateErrors = true
+	}
+}
+
+/**
+Makes proto writers store schema reference in the header, exposed by ProtoReader.Info.
+ */
+func WithSchemaRef(ref SchemaRef) Option {
+	return func(o *Options) {
+		o.SchemaRef = &ref
+	}
+}
+
+/**
+Makes JSON readers, split and join skip malformed lines and report them instead of failing.
+ */
+func Lenient() Option {
+	return func(o *Options) {
+		o.Lenient = true
+	}
+}
+
+/**
+Sets quiet period of ClaimFile for inbox files without ready marker.
+ */
+func WithQuietPeriod(d time.Duration) Option {
+	return func(o *Options) {
+		o.QuietPeriod = d
+	}
+}
+
+/**
+Makes operations resolve symlinks of input and output paths, split parts are created in the directory of the physical input.
+Atomic writes replace the target of symlinked destination regardless of this option.
+ */
+func ResolveSymlinks(enabled bool) Option {
+	return func(o *Options) {
+		o.ResolveSymlinks = enabled
+	}
+}
+
+/**
+Sets framing of proto records, VarintFraming streams are compatible with standard protobuf delimited format.
+ */
+func WithFraming(framing Framing) Option {
+	return func(o *Options) {
+		o.Framing = framing
+	}
+}
+
+/**
+Makes proto writers write header with per-record CRC32C checksums, readers verify them and return ErrChecksumMismatch.
+ */
+func WithChecksums() Option {
+	return func(o *Options) {
+		o.Checksums = true
+	}
+}
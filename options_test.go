@@ -0,0 +1,28 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+)
+
+func TestWithMessageType(t *testing.T) {
+	var o Options
+	WithMessageType(wrapperspb.String("x"))(&o)
+	if o.MessageType != "google.protobuf.StringValue" {
+		t.Fatalf("message type %s", o.MessageType)
+	}
+	var typed *wrapperspb.Int64Value
+	WithMessageType(typed)(&o)
+	if o.MessageType != "google.protobuf.Int64Value" {
+		t.Fatalf("typed nil message type %s", o.MessageType)
+	}
+	WithMessageType(nil)(&o)
+	if o.MessageType != "" {
+		t.Fatalf("nil message kept type %s", o.MessageType)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+/**
+Magic prefix of the packfile.
+ */
+var PackMagic = []byte{0xFF, 'F', 'S', 'K'}
+
+/**
+Current version of the packfile format.
+ */
+const PackVersion = 1
+
+/**
+Returned when packfile is malformed or has unknown version.
+ */
+var ErrInvalidPack = errors.New("fs: invalid packfile")
+
+/**
+PackEntryNotFoundError is returned when pack has no entry with the name.
+ */
+type PackEntryNotFoundError struct {
+	Name string
+}
+
+func (e *PackEntryNotFoundError) Error() string {
+	return fmt.Sprintf("fs: pack entry '%s' not found", e.Name)
+}
+
+/**
+Entry of the packfile. Offset and length are of the compressed segment, checksum is CRC32C of the segment.
+ */
+type PackEntry struct {
+	Name     string
+	Offset   int64
+	Length   int64
+	Size     int64
+	Codec    Compression
+	Checksum uint32
+}
+
+/**
+Base interface to append logical files in to packfile.
+Every segment is preceded by entry header and compressed independently, Close writes the trailing index.
+ */
+type PackWriter interface {
+
+	/*
+	Appends content of the reader as entry, name is sanitized with SanitizeEntryName and must be unique.
+	 */
+	Add(name string, r io.Reader) error
+
+	/*
+	Appends records of JSON reader as NDJSON entry.
+	 */
+	AddJsonRecords(name string, jr JsonReader) error
+
+	/*
+	Writes index and closes packfile.
+	 */
+	Close() error
+
+}
+
+/**
+Base interface of opened packfile, entries are read through the regular readers without extracting. Index is read once and cached,
+so opening entry takes a read of the segment. Packfile with truncated or missing index is recovered by scanning entry headers.
+ */
+type Pack interface {
+
+	/*
+	Gets entries in the order they were added.
+	 */
+	Entries() []PackEntry
+
+	/*
+	Checks if index was recovered by scanning entry headers.
+	 */
+	Recovered() bool
+
+	/*
+	Opens decompressed content of the entry, checksum is verified on EOF.
+	 */
+	Open(name string) (io.ReadCloser, error)
+
+	/*
+	Opens JSON entry.
+	 */
+	OpenJson(name string) (JsonReader, error)
+
+	/*
+	Opens protofile entry.
+	 */
+	OpenProto(name string) (ProtoReader, error)
+
+	/*
+	Opens CSV entry.
+	 */
+	OpenCsv(name string, valueProcessors ...CsvValueProcessor) (CsvReader, error)
+
+	/*
+	Extracts all entries in to directory, entry names are relative paths.
+	 */
+	UnpackTo(dir string) error
+
+	/*
+	Closes packfile.
+	 */
+	Close() error
+
+}
+
+/**
+Base interface for coalescing many small files in to packfile.
+ */
+type PackService interface {
+
+	/*
+	Creates packfile, entries are compressed by gzip with the compression level of the service.
+	 */
+	NewPackWriter(path string) (PackWriter, error)
+
+	/*
+	Opens packfile.
+	 */
+	OpenPack(path string) (Pack, error)
+
+	/*
+	Packs files of directory matching glob pattern, entry names are paths relative to the directory.
+	 */
+	PackDirectory(dir, pattern, packPath string) error
+
+}
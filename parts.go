@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+/**
+Description of the completed split part.
+ */
+type PartInfo struct {
+
+	/*
+	Index of the part starting from zero.
+	 */
+	Index int
+
+	/*
+	Number of records in the part.
+	 */
+	Records int64
+
+	/*
+	Size of the part file.
+	 */
+	Bytes int64
+
+	/*
+	Keys of the first and the last records, empty unless part key extractor is configured.
+	 */
+	FirstKey string
+	LastKey  string
+
+	/*
+	Hex SHA-256 of the part file content.
+	 */
+	SHA256 string
+
+	/*
+	Temporary path of the part before it is renamed to the final name.
+	 */
+	TempPath string
+}
+
+/**
+PartNameCollisionError is returned when two parts with different content finalize to the same name.
+ */
+type PartNameCollisionError struct {
+	Name   string
+	First  int
+	Second int
+}
+
+func (e *PartNameCollisionError) Error() string {
+	return fmt.Sprintf("fs: parts %d and %d with different content have the same name '%s'", e.First, e.Second, e.Name)
+}
+
+/**
+Splits records of the reader in to parts of at most limit records, it backs SplitJsonFile of the service that passes its part writer factory.
+Partition function formats path of each part and is called with SafeCallback. On any error or panic the current part is closed,
+all created parts are removed and the error is returned, so a failed split leaves no partial parts.
+ */
+func SplitJsonRecords(r JsonReader, limit int, partitionFn func(int) string, create func(path string) (JsonWriter, error)) ([]string, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("fs: split limit %d is not positive", limit)
+	}
+	var parts []string
+	var w JsonWriter
+	var count int
+	fail := func(err error) ([]string, error) {
+		if w != nil {
+			w.Close()
+		}
+		for _, part := range parts {
+			os.Remove(part)
+		}
+		return nil, err
+	}
+	for {
+		raw, err := r.ReadRaw()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if w == nil || count == limit {
+			if w != nil {
+				err := w.Close()
+				w = nil
+				if err != nil {
+					return fail(err)
+				}
+			}
+			var path string
+			index := len(parts)
+			if err := SafeCallback("SplitJsonFile", index, func() error {
+				path = partitionFn(index)
+				return nil
+			}); err != nil {
+				return fail(err)
+			}
+			if w, err = create(path); err != nil {
+				w = nil
+				return fail(err)
+			}
+			parts = append(parts, path)
+			count = 0
+		}
+		if err := w.WriteRaw(raw); err != nil {
+			return fail(err)
+		}
+		count++
+	}
+	if w != nil {
+		err := w.Close()
+		w = nil
+		if err != nil {
+			return fail(err)
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/**
+Operations of JSON patch, a pragmatic subset of RFC 6902 on dot paths.
+ */
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+)
+
+/**
+Single JSON patch operation, From is used only by move.
+ */
+type JsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+/**
+MissingPathError is returned by patch operation on missing path unless missing paths are skipped.
+ */
+type MissingPathError struct {
+	Op   string
+	Path string
+}
+
+func (e *MissingPathError) Error() string {
+	return fmt.Sprintf("fs: %s of missing path '%s'", e.Op, e.Path)
+}
+
+/**
+Applies patch to raw record at token level, untouched fields are copied verbatim keeping key order and number formatting.
+Operations on missing paths are skipped if skipMissing is true, otherwise MissingPathError is returned.
+ */
+func ApplyJsonPatch(raw json.RawMessage, patch []JsonPatchOp, skipMissing bool) (json.RawMessage, error) {
+	missing := func(op JsonPatchOp, path string) error {
+		if skipMissing {
+			return nil
+		}
+		return &MissingPathError{Op: op.Op, Path: path}
+	}
+	for _, op := range patch {
+		switch op.Op {
+		case PatchAdd:
+			out, err := setJsonField(raw, op.Path, op.Value)
+			if err != nil {
+				return nil, err
+			}
+			raw = out
+		case PatchRemove:
+			out, found, err := removeJsonField(raw, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				if err := missing(op, op.Path); err != nil {
+					return nil, err
+				}
+			}
+			raw = out
+		case PatchReplace:
+			_, found, err := jsonField(raw, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				if err := missing(op, op.Path); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if raw, err = setJsonField(raw, op.Path, op.Value); err != nil {
+				return nil, err
+			}
+		case PatchMove:
+			value, found, err := jsonField(raw, op.From)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				if err := missing(op, op.From); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value = append(json.RawMessage(nil), value...)
+			if raw, _, err = removeJsonField(raw, op.From); err != nil {
+				return nil, err
+			}
+			if raw, err = setJsonField(raw, op.Path, value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("fs: unsupported patch operation '%s'", op.Op)
+		}
+	}
+	return raw, nil
+}
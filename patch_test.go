@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeJsonNumbers(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+/*
+Reference patch implementation on decoded records used to check ApplyJsonPatch.
+ */
+func referencePatch(record map[string]interface{}, patch []JsonPatchOp, skipMissing bool) error {
+	parent := func(path string, create bool) (map[string]interface{}, string, error) {
+		obj := record
+		parts := strings.Split(path, ".")
+		for _, key := range parts[:len(parts)-1] {
+			next, ok := obj[key]
+			if !ok {
+				if !create {
+					return nil, "", nil
+				}
+				next = make(map[string]interface{})
+				obj[key] = next
+			}
+			child, ok := next.(map[string]interface{})
+			if !ok {
+				return nil, "", errNotJsonObject
+			}
+			obj = child
+		}
+		return obj, parts[len(parts)-1], nil
+	}
+	missing := func(op JsonPatchOp, path string) error {
+		if skipMissing {
+			return nil
+		}
+		return &MissingPathError{Op: op.Op, Path: path}
+	}
+	for _, op := range patch {
+		switch op.Op {
+		case PatchAdd, PatchReplace:
+			obj, key, err := parent(op.Path, op.Op == PatchAdd)
+			if err != nil {
+				return err
+			}
+			if _, ok := obj[key]; op.Op == PatchReplace && !ok {
+				if err := missing(op, op.Path); err != nil {
+					return err
+				}
+				continue
+			}
+			value, err := decodeJsonNumbers(op.Value)
+			if err != nil {
+				return err
+			}
+			obj[key] = value
+		case PatchRemove, PatchMove:
+			from := op.Path
+			if op.Op == PatchMove {
+				from = op.From
+			}
+			obj, key, err := parent(from, false)
+			if err != nil {
+				return err
+			}
+			value, ok := obj[key]
+			if !ok {
+				if err := missing(op, from); err != nil {
+					return err
+				}
+				continue
+			}
+			delete(obj, key)
+			if op.Op == PatchMove {
+				if obj, key, err = parent(op.Path, true); err != nil {
+					return err
+				}
+				obj[key] = value
+			}
+		}
+	}
+	return nil
+}
+
+func randomPatch(r *rand.Rand) []JsonPatchOp {
+	paths := []string{"id", "v", "x y", "meta", "meta.v", "meta.id", "meta.meta.v", "new.v"}
+	ops := []string{PatchAdd, PatchRemove, PatchReplace, PatchMove}
+	patch := make([]JsonPatchOp, 1+r.Intn(3))
+	for i := range patch {
+		value, _ := json.Marshal(randomJsonValue(r, 1))
+		patch[i] = JsonPatchOp{Op: ops[r.Intn(len(ops))], Path: paths[r.Intn(len(paths))], From: paths[r.Intn(len(paths))], Value: value}
+	}
+	return patch
+}
+
+func TestApplyJsonPatchMatchesReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	applied := 0
+	for i := 0; i < 5000; i++ {
+		raw, err := json.Marshal(randomJsonObject(r, 2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		patch := randomPatch(r)
+		skipMissing := r.Intn(2) == 0
+		got, err := ApplyJsonPatch(raw, patch, skipMissing)
+
+		decoded, _ := decodeJsonNumbers(raw)
+		want := decoded.(map[string]interface{})
+		wantErr := referencePatch(want, patch, skipMissing)
+		if (err != nil) != (wantErr != nil) {
+			t.Fatalf("patch %+v of %s: error %v, reference error %v", patch, raw, err, wantErr)
+		}
+		var missingErr, wantMissing *MissingPathError
+		if errors.As(wantErr, &wantMissing) && (!errors.As(err, &missingErr) || *missingErr != *wantMissing) {
+			t.Fatalf("patch %+v of %s: error %v, want %v", patch, raw, err, wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		applied++
+		result, err := decodeJsonNumbers(got)
+		if err != nil {
+			t.Fatalf("patch %+v of %s produced invalid JSON %s: %v", patch, raw, got, err)
+		}
+		if !reflect.DeepEqual(result, interface{}(want)) {
+			t.Fatalf("patch %+v of %s gave %s, reference %v", patch, raw, got, want)
+		}
+	}
+	if applied < 1000 {
+		t.Fatalf("only %d patches applied", applied)
+	}
+}
+
+func TestApplyJsonPatchKeepsFormatting(t *testing.T) {
+	raw := json.RawMessage(`{"z": 1.50, "old": "x", "id": 18446744073709551615, "a": {"b": 1e3}}`)
+	got, err := ApplyJsonPatch(raw, []JsonPatchOp{
+		{Op: PatchMove, From: "old", Path: "source"},
+		{Op: PatchReplace, Path: "a.b", Value: json.RawMessage(`2.0`)},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"z": 1.50, "id": 18446744073709551615, "a": {"b": 2.0},"source":"x"}`; string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestApplyJsonPatchUnsupported(t *testing.T) {
+	if _, err := ApplyJsonPatch(json.RawMessage(`{}`), []JsonPatchOp{{Op: "copy", Path: "a"}}, true); err == nil {
+		t.Fatal("unsupported operation accepted")
+	}
+}
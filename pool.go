@@ -0,0 +1,313 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+/**
+Returned by WriterPool after Close.
+ */
+var ErrPoolClosed = errors.New("fs: writer pool is closed")
+
+/**
+Default number of open writers of the pool.
+ */
+const DefaultWriterPoolBudget = 64
+
+/**
+Opens writer of the pool key, reopen is true if the key was already opened and evicted, then writer must append.
+ */
+type WriterOpenFn func(key string, reopen bool) (io.Closer, error)
+
+/**
+WriterEvictionError is returned when evicted writer of the key failed to close, the data of the key written before eviction could be incomplete.
+ */
+type WriterEvictionError struct {
+	Key string
+	Err error
+}
+
+func (e *WriterEvictionError) Error() string {
+	return fmt.Sprintf("fs: close of evicted writer '%s': %v", e.Key, e.Err)
+}
+
+func (e *WriterEvictionError) Unwrap() error {
+	return e.Err
+}
+
+/**
+WriterPool caps number of open writers shared by all partitioned outputs of the service.
+Least recently used writer that is not acquired is closed, so compressed writer finalizes its member, and reopened on demand.
+If all writers are acquired, Acquire waits for a release, a goroutine must not hold more acquired writers than the budget.
+Writers are opened and closed outside of the pool lock, evicted writer counts against the budget until it is closed and Acquire of its key waits for the close.
+Close error of evicted writer is returned by the next Acquire or Finish of its key, or by Close.
+ */
+type WriterPool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	budget  int
+	hook    MetricsHook
+	lru     *list.List
+	entries map[string]*list.Element
+	opened  map[string]bool
+	failed  map[string]error
+	order   []string
+	closed  bool
+}
+
+type poolEntry struct {
+	key   string
+	w     io.Closer
+	refs    int
+	ready   bool
+	closing bool
+}
+
+/**
+Creates pool with the budget of open writers, hook could be nil.
+ */
+func NewWriterPool(budget int, hook MetricsHook) *WriterPool {
+	if budget <= 0 {
+		budget = DefaultWriterPoolBudget
+	}
+	t := &WriterPool{
+		budget:  budget,
+		hook:    hook,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		opened:  make(map[string]bool),
+		failed:  make(map[string]error),
+	}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+/*
+Gets open writer of the key, opening it if needed. Release function must be called after use.
+Concurrent Acquire of the key being opened waits for the open, open failure or panic leaves the key free.
+ */
+func (t *WriterPool) Acquire(key string, open WriterOpenFn) (io.Closer, func(), error) {
+	t.mu.Lock()
+	for {
+		if t.closed {
+			t.mu.Unlock()
+			return nil, nil, ErrPoolClosed
+		}
+		if err, ok := t.failed[key]; ok {
+			delete(t.failed, key)
+			t.mu.Unlock()
+			return nil, nil, err
+		}
+		if el, ok := t.entries[key]; ok {
+			e := el.Value.(*poolEntry)
+			if !e.ready || e.closing {
+				t.cond.Wait()
+				continue
+			}
+			e.refs++
+			t.lru.MoveToFront(el)
+			t.mu.Unlock()
+			return e.w, t.releaseFn(e), nil
+		}
+		if t.lru.Len() < t.budget {
+			break
+		}
+		if victim := t.evict(); victim != nil {
+			t.mu.Unlock()
+			t.closeEvicted(victim)
+			t.mu.Lock()
+			continue
+		}
+		t.cond.Wait()
+	}
+	reopen := t.opened[key]
+	e := &poolEntry{key: key, refs: 1}
+	el := t.lru.PushFront(e)
+	t.entries[key] = el
+	t.mu.Unlock()
+
+	done := false
+	defer func() {
+		if !done {
+			t.mu.Lock()
+			t.lru.Remove(el)
+			delete(t.entries, key)
+			t.mu.Unlock()
+			t.cond.Broadcast()
+		}
+	}()
+	w, err := open(key, reopen)
+	if err != nil {
+		return nil, nil, err
+	}
+	done = true
+
+	t.mu.Lock()
+	e.w, e.ready = w, true
+	t.opened[key] = true
+	t.gauge()
+	t.mu.Unlock()
+	t.cond.Broadcast()
+	if reopen {
+		t.inc(MetricWriterPoolReopen)
+	}
+	return w, t.releaseFn(e), nil
+}
+
+func (t *WriterPool) releaseFn(e *poolEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			e.refs--
+			t.mu.Unlock()
+			t.cond.Broadcast()
+		})
+	}
+}
+
+/*
+Marks least recently used writer that is not acquired as closing, caller closes it without the lock.
+The entry stays in the pool until closeEvicted, so it counts against the budget and its key is not reopened while closing.
+ */
+func (t *WriterPool) evict() *list.Element {
+	for el := t.lru.Back(); el != nil; el = el.Prev() {
+		e := el.Value.(*poolEntry)
+		if e.refs > 0 || !e.ready || e.closing {
+			continue
+		}
+		e.closing = true
+		return el
+	}
+	return nil
+}
+
+func (t *WriterPool) closeEvicted(el *list.Element) {
+	e := el.Value.(*poolEntry)
+	t.inc(MetricWriterPoolEviction)
+	err := e.w.Close()
+	t.mu.Lock()
+	t.lru.Remove(el)
+	delete(t.entries, e.key)
+	if err != nil {
+		t.fail(e.key, &WriterEvictionError{Key: e.key, Err: err})
+	}
+	t.gauge()
+	t.mu.Unlock()
+	t.cond.Broadcast()
+}
+
+/*
+Records failure of the key in order, so Close returns the first one.
+ */
+func (t *WriterPool) fail(key string, err error) {
+	t.failed[key] = err
+	t.order = append(t.order, key)
+}
+
+/*
+Gets number of open writers including evicted writers being closed.
+ */
+func (t *WriterPool) Open() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lru.Len()
+}
+
+/*
+Closes writer of the key if open, the next Acquire opens it as new. Returns close error of the writer or of its earlier eviction.
+ */
+func (t *WriterPool) Finish(key string) error {
+	t.mu.Lock()
+	el, ok := t.entries[key]
+	for ok && (!el.Value.(*poolEntry).ready || el.Value.(*poolEntry).closing) {
+		t.cond.Wait()
+		el, ok = t.entries[key]
+	}
+	delete(t.opened, key)
+	failed := t.failed[key]
+	delete(t.failed, key)
+	if !ok {
+		t.mu.Unlock()
+		return failed
+	}
+	t.lru.Remove(el)
+	delete(t.entries, key)
+	t.gauge()
+	t.mu.Unlock()
+	t.cond.Broadcast()
+	if err := el.Value.(*poolEntry).w.Close(); err != nil {
+		return err
+	}
+	return failed
+}
+
+/*
+Closes the pool, waits until all acquired writers are released and closes them. Returns the first close or eviction error.
+Close must not be called by a goroutine holding an acquired writer.
+ */
+func (t *WriterPool) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.cond.Broadcast()
+	for t.busy() {
+		t.cond.Wait()
+	}
+	var writers []io.Closer
+	for el := t.lru.Front(); el != nil; el = el.Next() {
+		writers = append(writers, el.Value.(*poolEntry).w)
+	}
+	var first error
+	for _, key := range t.order {
+		if err, ok := t.failed[key]; ok {
+			first = err
+			break
+		}
+	}
+	t.lru.Init()
+	t.entries = nil
+	t.failed = nil
+	t.order = nil
+	t.gauge()
+	t.mu.Unlock()
+	for _, w := range writers {
+		if err := w.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (t *WriterPool) busy() bool {
+	for el := t.lru.Front(); el != nil; el = el.Next() {
+		if e := el.Value.(*poolEntry); e.refs > 0 || !e.ready || e.closing {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *WriterPool) inc(name string) {
+	if t.hook != nil {
+		t.hook.IncCounter(name, 1)
+	}
+}
+
+func (t *WriterPool) gauge() {
+	if t.hook != nil {
+		t.hook.SetGauge(MetricWriterPoolOpen, int64(t.lru.Len()))
+	}
+}
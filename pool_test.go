@@ -0,0 +1,348 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type poolWriter struct {
+	closed atomic.Int32
+	err    error
+}
+
+func (w *poolWriter) Close() error {
+	w.closed.Add(1)
+	return w.err
+}
+
+func TestWriterPoolOpenWithoutLock(t *testing.T) {
+	p := NewWriterPool(1, nil)
+	_, release, err := p.Acquire("a", func(key string, reopen bool) (io.Closer, error) {
+		p.Open()
+		return &poolWriter{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	evicting := &poolWriter{}
+	_, release, err = p.Acquire("b", func(key string, reopen bool) (io.Closer, error) {
+		return evicting, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriterPoolEvictionError(t *testing.T) {
+	p := NewWriterPool(1, nil)
+	failure := errors.New("flush failed")
+	_, release, _ := p.Acquire("a", func(key string, reopen bool) (io.Closer, error) {
+		return &poolWriter{err: failure}, nil
+	})
+	release()
+	_, release, err := p.Acquire("b", func(key string, reopen bool) (io.Closer, error) {
+		return &poolWriter{}, nil
+	})
+	if err != nil {
+		t.Fatalf("eviction error reported to unrelated key: %v", err)
+	}
+	release()
+	var evictErr *WriterEvictionError
+	if _, _, err := p.Acquire("a", nil); !errors.As(err, &evictErr) || evictErr.Key != "a" || !errors.Is(err, failure) {
+		t.Fatalf("want eviction error of a, got %v", err)
+	}
+	_, release, err = p.Acquire("a", func(key string, reopen bool) (io.Closer, error) {
+		if !reopen {
+			t.Error("want reopen")
+		}
+		return &poolWriter{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriterPoolOpenPanic(t *testing.T) {
+	p := NewWriterPool(1, nil)
+	func() {
+		defer func() {
+			recover()
+		}()
+		p.Acquire("a", func(key string, reopen bool) (io.Closer, error) {
+			panic("boom")
+		})
+	}()
+	if p.Open() != 0 {
+		t.Fatalf("panicked open left %d writers", p.Open())
+	}
+	_, release, err := p.Acquire("a", func(key string, reopen bool) (io.Closer, error) {
+		return &poolWriter{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	p.Close()
+}
+
+func TestWriterPoolCloseWaitsForRelease(t *testing.T) {
+	p := NewWriterPool(2, nil)
+	w := &poolWriter{}
+	_, release, _ := p.Acquire("a", func(key string, reopen bool) (io.Closer, error) {
+		return w, nil
+	})
+	done := make(chan error)
+	go func() {
+		done <- p.Close()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if w.closed.Load() != 0 {
+		t.Fatal("acquired writer closed")
+	}
+	if _, _, err := p.Acquire("b", nil); err != ErrPoolClosed {
+		t.Fatalf("want ErrPoolClosed, got %v", err)
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if w.closed.Load() != 1 {
+		t.Fatalf("writer closed %d times", w.closed.Load())
+	}
+}
+
+/*
+Counts live writers of the pool opener and the peak, closing writer is live until its Close returns.
+ */
+type liveCounter struct {
+	live atomic.Int32
+	peak atomic.Int32
+}
+
+func (c *liveCounter) opened() {
+	n := c.live.Add(1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+type countedPoolWriter struct {
+	poolWriter
+	c *liveCounter
+}
+
+func (w *countedPoolWriter) Close() error {
+	time.Sleep(time.Millisecond)
+	w.c.live.Add(-1)
+	return w.poolWriter.Close()
+}
+
+func TestWriterPoolConcurrent(t *testing.T) {
+	p := NewWriterPool(3, nil)
+	var c liveCounter
+	var mu sync.Mutex
+	var writers []*countedPoolWriter
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				_, release, err := p.Acquire(fmt.Sprint((g+i)%7), func(key string, reopen bool) (io.Closer, error) {
+					c.opened()
+					w := &countedPoolWriter{c: &c}
+					mu.Lock()
+					writers = append(writers, w)
+					mu.Unlock()
+					return w, nil
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				release()
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if peak := c.peak.Load(); peak > 3 {
+		t.Fatalf("%d live writers over budget", peak)
+	}
+	for _, w := range writers {
+		if w.closed.Load() != 1 {
+			t.Fatalf("writer closed %d times", w.closed.Load())
+		}
+	}
+}
+
+func TestWriterPoolFirstFailure(t *testing.T) {
+	for run := 0; run < 20; run++ {
+		p := NewWriterPool(1, nil)
+		for _, key := range []string{"a", "b", "c", "d"} {
+			_, release, err := p.Acquire(key, func(key string, reopen bool) (io.Closer, error) {
+				return &poolWriter{err: errors.New(key)}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			release()
+		}
+		var evictErr *WriterEvictionError
+		if err := p.Close(); !errors.As(err, &evictErr) || evictErr.Key != "a" {
+			t.Fatalf("want the first eviction failure, got %v", err)
+		}
+	}
+}
+
+type gzipPartWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	gz   *gzip.Writer
+}
+
+func (w *gzipPartWriter) writeLine(line string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := io.WriteString(w.gz, line+"\n")
+	return err
+}
+
+func (w *gzipPartWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.gz.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+func openFds() (int, error) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	return len(entries), err
+}
+
+func TestWriterPoolStress(t *testing.T) {
+	const budget, partitions, goroutines, rounds = 16, 500, 8, 4
+	dir := t.TempDir()
+	// first file open initializes the poller descriptors of the runtime
+	if f, err := os.Create(filepath.Join(dir, "warmup")); err == nil {
+		f.Close()
+	}
+	baseline, err := openFds()
+	if err != nil {
+		t.Skip("open descriptors are not countable:", err)
+	}
+	p := NewWriterPool(budget, nil)
+	var mu sync.Mutex
+	peak := 0
+	open := func(key string, reopen bool) (io.Closer, error) {
+		flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		if !reopen {
+			flag |= os.O_TRUNC
+		}
+		f, err := os.OpenFile(filepath.Join(dir, key+".gz"), flag, 0644)
+		if err != nil {
+			return nil, err
+		}
+		// the opened descriptor is counted with writers being evicted, one reading of /proc/self/fd at a time
+		mu.Lock()
+		if n, _ := openFds(); n-baseline-1 > peak {
+			peak = n - baseline - 1
+		}
+		mu.Unlock()
+		return &gzipPartWriter{file: f, gz: gzip.NewWriter(f)}, nil
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				for i := 0; i < partitions; i++ {
+					key := fmt.Sprintf("p%03d", (i*7+g*31)%partitions)
+					w, release, err := p.Acquire(key, open)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					err = w.(*gzipPartWriter).writeLine(fmt.Sprintf("%s %d %d", key, g, r))
+					release()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if peak > budget {
+		t.Fatalf("%d descriptors open, budget %d", peak, budget)
+	}
+	if n, _ := openFds(); n > baseline {
+		n -= baseline
+		t.Fatalf("%d descriptors left open", n)
+	}
+	for i := 0; i < partitions; i++ {
+		key := fmt.Sprintf("p%03d", i)
+		f, err := os.Open(filepath.Join(dir, key+".gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(key, err)
+		}
+		seen := make(map[string]bool)
+		scanner := bufio.NewScanner(gz)
+		for scanner.Scan() {
+			var k string
+			var g, r int
+			if _, err := fmt.Sscanf(scanner.Text(), "%s %d %d", &k, &g, &r); err != nil || k != key || seen[scanner.Text()] {
+				t.Fatalf("%s: bad or duplicate line %q", key, scanner.Text())
+			}
+			seen[scanner.Text()] = true
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatal(key, err)
+		}
+		gz.Close()
+		f.Close()
+		if len(seen) != goroutines*rounds {
+			t.Fatalf("%s: %d lines, want %d", key, len(seen), goroutines*rounds)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+/*
+Builds message `Event { google.protobuf.Timestamp at = 1; Status status = 2; string name = 3; }` with enum `Status { UNKNOWN = 0; ACTIVE = 1; }`.
+*/
+func eventDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("fs/event_test.proto"),
+		Package:    proto.String("fstest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("ACTIVE"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Event"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("at"), JsonName: proto.String("at"), Number: proto.Int32(1), Label: label, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Timestamp")},
+				{Name: proto.String("status"), JsonName: proto.String("status"), Number: proto.Int32(2), Label: label, Type: descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(), TypeName: proto.String(".fstest.Status")},
+				{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(3), Label: label, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Messages().ByName("Event")
+}
+
+func newEvent(desc protoreflect.MessageDescriptor) proto.Message {
+	m := dynamicpb.NewMessage(desc)
+	at := timestamppb.New(time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC))
+	m.Set(desc.Fields().ByName("at"), protoreflect.ValueOfMessage(at.ProtoReflect()))
+	m.Set(desc.Fields().ByName("status"), protoreflect.ValueOfEnum(1))
+	return m
+}
+
+func TestProtoWriteUsesMarshalOptions(t *testing.T) {
+	desc := eventDescriptor(t)
+	for _, c := range []struct {
+		options protojson.MarshalOptions
+		want    string
+	}{
+		{protojson.MarshalOptions{}, `{"at":"2023-05-01T10:30:00Z","status":"ACTIVE"}`},
+		{protojson.MarshalOptions{UseEnumNumbers: true, EmitUnpopulated: true}, `{"at":"2023-05-01T10:30:00Z","status":1,"name":""}`},
+	} {
+		validated := &memJsonWriter{options: OptionsSnapshot{MarshalOptions: c.options}}
+		if err := NewValidatingJsonWriter(validated, nil).Write(newEvent(desc)); err != nil {
+			t.Fatal(err)
+		}
+		acked := &memJsonWriter{options: OptionsSnapshot{MarshalOptions: c.options}}
+		if _, err := NewAckingJsonWriter(acked, 0).Write(newEvent(desc)); err != nil {
+			t.Fatal(err)
+		}
+		for name, w := range map[string]*memJsonWriter{"validating": validated, "acking": acked} {
+			if len(w.records) != 1 || compactJson(t, w.records[0]) != c.want {
+				t.Errorf("%s writer with %+v wrote %s, want %s", name, c.options, w.records, c.want)
+			}
+		}
+	}
+}
+
+func compactJson(t *testing.T, raw []byte) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := json.Compact(&out, raw); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
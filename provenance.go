@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "encoding/json"
+
+/**
+Default name of the provenance field.
+ */
+const DefaultProvenanceField = "_prov"
+
+/**
+Single pipeline stage origin of the record.
+ */
+type ProvenanceStep struct {
+	Source string `json:"source"`
+	Line   int64  `json:"line"`
+}
+
+/**
+Provenance of the record: origin in the latest stage and origins in previous stages, the oldest first.
+ */
+type Provenance struct {
+	Source string           `json:"source"`
+	Line   int64            `json:"line"`
+	Chain  []ProvenanceStep `json:"chain,omitempty"`
+}
+
+/**
+Gets provenance of the record, returns false if the record has no provenance field.
+ */
+func RecordProvenance(raw json.RawMessage, field string) (Provenance, bool, error) {
+	var p Provenance
+	value, ok, err := jsonField(raw, field)
+	if err != nil || !ok {
+		return p, false, err
+	}
+	if err := json.Unmarshal(value, &p); err != nil {
+		return p, false, err
+	}
+	return p, true, nil
+}
+
+/**
+Stamps record with source and line at raw level, existing provenance is moved to the chain. Other fields are not changed.
+ */
+func StampProvenance(raw json.RawMessage, field string, source string, line int64) (json.RawMessage, error) {
+	p, ok, err := RecordProvenance(raw, field)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		p.Chain = append(p.Chain, ProvenanceStep{Source: p.Source, Line: p.Line})
+	}
+	p.Source, p.Line = source, line
+	value, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return setJsonField(raw, field, value)
+}
+
+/**
+Creates write hook that removes provenance field at the final output stage.
+ */
+func StripProvenance(field string) JsonWriteHook {
+	return func(raw json.RawMessage) (json.RawMessage, error) {
+		out, _, err := removeJsonField(raw, field)
+		return out, err
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStampProvenanceChain(t *testing.T) {
+	raw := json.RawMessage(`{"id": 1.50,"name":"a"}`)
+	raw, err := StampProvenance(raw, DefaultProvenanceField, "raw.json", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err = StampProvenance(raw, DefaultProvenanceField, "clean.json", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok, err := RecordProvenance(raw, DefaultProvenanceField)
+	if err != nil || !ok {
+		t.Fatalf("no provenance in %s: %v", raw, err)
+	}
+	want := Provenance{Source: "clean.json", Line: 1, Chain: []ProvenanceStep{{Source: "raw.json", Line: 3}}}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("provenance %+v, want %+v", p, want)
+	}
+	stripped, err := StripProvenance(DefaultProvenanceField)(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stripped) != `{"id": 1.50,"name":"a"}` {
+		t.Fatalf("stripped %s", stripped)
+	}
+}
+
+func TestRecordProvenanceMissing(t *testing.T) {
+	if _, ok, err := RecordProvenance(json.RawMessage(`{"id":1}`), DefaultProvenanceField); ok || err != nil {
+		t.Fatalf("found provenance: %v, %v", ok, err)
+	}
+	if _, _, err := RecordProvenance(json.RawMessage(`{"_prov":"x"}`), DefaultProvenanceField); err == nil {
+		t.Fatal("malformed provenance accepted")
+	}
+	if out, err := StripProvenance(DefaultProvenanceField)(json.RawMessage(`{"id":1}`)); err != nil || string(out) != `{"id":1}` {
+		t.Fatalf("strip without provenance: %s, %v", out, err)
+	}
+}
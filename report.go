@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"time"
+)
+
+/**
+Suffix of the operation report written next to the output.
+ */
+const ReportSuffix = ".report.json"
+
+/**
+Input file of the operation.
+ */
+type ReportInput struct {
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+/**
+Machine-readable receipt of the bulk operation, written even if operation failed.
+ */
+type OperationReport struct {
+
+	/*
+	Operation name, for example `SplitJsonFile`.
+	 */
+	Operation string `json:"operation"`
+
+	/*
+	Inputs with fingerprints.
+	 */
+	Inputs []ReportInput `json:"inputs"`
+
+	/*
+	Output file, or the first part for split.
+	 */
+	Output string `json:"output"`
+
+	/*
+	Produced parts of split.
+	 */
+	Parts []string `json:"parts,omitempty"`
+
+	/*
+	Options in effect.
+	 */
+	Options OptionsSnapshot `json:"options"`
+
+	/*
+	Number of records and uncompressed bytes processed.
+	 */
+	Records int64 `json:"records"`
+	Bytes   int64 `json:"bytes"`
+
+	/*
+	Start time and duration of the operation.
+	 */
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+
+	/*
+	Join concatenated compressed members without recompression.
+	 */
+	FastPath bool `json:"fastPath,omitempty"`
+
+	/*
+	Error of the operation, empty on success.
+	 */
+	Error string `json:"error,omitempty"`
+}
+
+/**
+Writes report atomically via temp file in the same directory and rename, r is the random source of temp name and could be nil.
+ */
+func WriteReport(filePath string, report *OperationReport, r *rand.Rand) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fd, err := CreateAtomicTemp(filePath, r)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(data); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		return err
+	}
+	return CommitAtomicTemp(fd, filePath)
+}
+
+/**
+Loads report from the file.
+ */
+func LoadReport(filePath string) (*OperationReport, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	report := new(OperationReport)
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReportRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json"+ReportSuffix)
+	want := &OperationReport{
+		Operation: "SplitJsonFile",
+		Inputs:    []ReportInput{{Path: "in.json", Fingerprint: "v1:1:aa:bb"}},
+		Output:    "out-0001.json",
+		Parts:     []string{"out-0001.json", "out-0002.json"},
+		Records:   10,
+		Bytes:     100,
+		Started:   time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC),
+		Duration:  time.Second,
+		Error:     "disk full",
+	}
+	if err := WriteReport(path, want, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded %+v, want %+v", got, want)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("temp files left: %v", entries)
+	}
+}
+
+func TestLoadReportMissing(t *testing.T) {
+	if _, err := LoadReport(filepath.Join(t.TempDir(), "none")); !os.IsNotExist(err) {
+		t.Fatalf("want not exist, got %v", err)
+	}
+}
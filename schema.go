@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"strings"
+	"sync"
+)
+
+/**
+SchemaRef points to the message schema in the registry, stored in the protofile header.
+ */
+type SchemaRef struct {
+	Registry string `json:"registry,omitempty"`
+	ID       string `json:"id"`
+	Version  string `json:"version,omitempty"`
+}
+
+func (r SchemaRef) String() string {
+	s := r.ID
+	if r.Version != "" {
+		s += "@" + r.Version
+	}
+	if r.Registry != "" {
+		s = r.Registry + "/" + s
+	}
+	return s
+}
+
+/**
+SchemaResolver fetches descriptor of the referenced schema, implemented by the user.
+ */
+type SchemaResolver interface {
+
+	/*
+	Resolves schema reference in to message descriptor.
+	 */
+	ResolveSchema(ref SchemaRef) (protoreflect.MessageDescriptor, error)
+
+}
+
+/**
+SchemaMismatchError is returned when holder descriptor differs from the referenced schema.
+Fields lists fields present only in one of descriptors as `+name` for schema and `-name` for holder.
+ */
+type SchemaMismatchError struct {
+	Ref    SchemaRef
+	Want   string
+	Got    string
+	Fields []string
+}
+
+func (e *SchemaMismatchError) Error() string {
+	if e.Want != e.Got {
+		return fmt.Sprintf("fs: schema %s is '%s', holder is '%s'", e.Ref, e.Want, e.Got)
+	}
+	return fmt.Sprintf("fs: schema %s of '%s' differs in fields: %s", e.Ref, e.Want, strings.Join(e.Fields, ", "))
+}
+
+/**
+Compares full names and field presence of descriptors, returns nil if they match.
+ */
+func CompareSchema(ref SchemaRef, schema, holder protoreflect.MessageDescriptor) error {
+	e := &SchemaMismatchError{Ref: ref, Want: string(schema.FullName()), Got: string(holder.FullName())}
+	if e.Want != e.Got {
+		return e
+	}
+	e.Fields = append(missingFields(schema, holder, "+"), missingFields(holder, schema, "-")...)
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+func missingFields(from, in protoreflect.MessageDescriptor, sign string) []string {
+	var list []string
+	fields := from.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		other := in.Fields().ByNumber(fd.Number())
+		if other == nil || other.Name() != fd.Name() {
+			list = append(list, sign+string(fd.Name()))
+		}
+	}
+	return list
+}
+
+/**
+InMemorySchemaResolver keeps descriptors in memory, safe for concurrent use.
+ */
+type InMemorySchemaResolver struct {
+	mu      sync.RWMutex
+	schemas map[SchemaRef]protoreflect.MessageDescriptor
+}
+
+/**
+Creates empty in-memory resolver.
+ */
+func NewInMemorySchemaResolver() *InMemorySchemaResolver {
+	return &InMemorySchemaResolver{schemas: make(map[SchemaRef]protoreflect.MessageDescriptor)}
+}
+
+/*
+Registers descriptor under the reference.
+ */
+func (t *InMemorySchemaResolver) Register(ref SchemaRef, desc protoreflect.MessageDescriptor) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.schemas[ref] = desc
+}
+
+func (t *InMemorySchemaResolver) ResolveSchema(ref SchemaRef) (protoreflect.MessageDescriptor, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if desc, ok := t.schemas[ref]; ok {
+		return desc, nil
+	}
+	return nil, fmt.Errorf("fs: schema %s not found", ref)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"reflect"
+	"testing"
+)
+
+func orderDescriptor(t *testing.T, fields ...string) protoreflect.MessageDescriptor {
+	t.Helper()
+	msg := &descriptorpb.DescriptorProto{Name: proto.String("Order")}
+	for i, name := range fields {
+		msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(int32(i + 1)),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		})
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String("fs/order_test.proto"),
+		Package:     proto.String("fstest"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{msg},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Messages().Get(0)
+}
+
+func TestCompareSchema(t *testing.T) {
+	ref := SchemaRef{Registry: "reg", ID: "orders", Version: "2"}
+	schema := orderDescriptor(t, "id", "amount", "currency")
+	if err := CompareSchema(ref, schema, orderDescriptor(t, "id", "amount", "currency")); err != nil {
+		t.Fatal(err)
+	}
+	err := CompareSchema(ref, schema, orderDescriptor(t, "id", "total"))
+	var mismatch *SchemaMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("want SchemaMismatchError, got %v", err)
+	}
+	if want := []string{"+amount", "+currency", "-total"}; !reflect.DeepEqual(mismatch.Fields, want) {
+		t.Fatalf("fields %v, want %v", mismatch.Fields, want)
+	}
+	err = CompareSchema(ref, schema, (&wrapperspb.StringValue{}).ProtoReflect().Descriptor())
+	if !errors.As(err, &mismatch) || mismatch.Got != "google.protobuf.StringValue" || len(mismatch.Fields) != 0 {
+		t.Fatalf("want name mismatch, got %v", err)
+	}
+}
+
+func TestInMemorySchemaResolver(t *testing.T) {
+	r := NewInMemorySchemaResolver()
+	ref := SchemaRef{ID: "orders", Version: "1"}
+	desc := orderDescriptor(t, "id")
+	r.Register(ref, desc)
+	if got, err := r.ResolveSchema(ref); err != nil || got != desc {
+		t.Fatalf("resolved %v, %v", got, err)
+	}
+	if _, err := r.ResolveSchema(SchemaRef{ID: "orders", Version: "2"}); err == nil {
+		t.Fatal("resolved unregistered version")
+	}
+	if got := (SchemaRef{Registry: "reg", ID: "orders", Version: "1"}).String(); got != "reg/orders@1" {
+		t.Fatalf("ref string %s", got)
+	}
+}
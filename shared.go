@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "errors"
+
+/**
+Returned by readers of the shared source invalidated by Close and by NewReader after Close.
+ */
+var ErrSharedSourceClosed = errors.New("fs: shared source is closed")
+
+/**
+SharedJsonSource holds one file descriptor and vends independent readers over it.
+Each reader keeps its own offset and reads with ReadAt, compressed files are decompressed per reader.
+ */
+type SharedJsonSource interface {
+
+	/*
+	Creates new reader positioned at the start of the file.
+	 */
+	NewReader() (JsonReader, error)
+
+	/*
+	Gets number of readers that are not closed yet.
+	 */
+	Readers() int
+
+	/*
+	Waits for outstanding readers to close and closes the descriptor.
+	With InvalidateSharedReaders option outstanding readers are invalidated instead and return ErrSharedSourceClosed.
+	 */
+	Close() error
+
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "time"
+
+/**
+Suffix of the statistics sidecar file.
+ */
+const StatsSidecarSuffix = ".stats.json"
+
+/**
+Cumulative statistics of the file maintained in sidecar by append writers.
+ */
+type FileStats struct {
+
+	/*
+	Total number of records.
+	 */
+	Records int64 `json:"records"`
+
+	/*
+	Total uncompressed bytes of records.
+	 */
+	RawBytes int64 `json:"rawBytes"`
+
+	/*
+	Size of the file when sidecar was written, used with FileModTime to detect stale sidecar.
+	 */
+	FileSize int64 `json:"fileSize"`
+
+	/*
+	Modification time of the file when sidecar was written.
+	 */
+	FileModTime time.Time `json:"fileModTime"`
+
+	/*
+	Timestamps of the first and the last records, nil without timestamp extractor.
+	 */
+	FirstTimestamp *time.Time `json:"firstTimestamp,omitempty"`
+	LastTimestamp  *time.Time `json:"lastTimestamp,omitempty"`
+
+	/*
+	Time of the last sidecar update.
+	 */
+	Updated time.Time `json:"updated"`
+}
+
+/**
+Base interface for statistics sidecar files.
+ */
+type SidecarService interface {
+
+	/*
+	Reads `<file>.stats.json` sidecar. Returns false if sidecar does not exist or is stale because file size or mtime do not match,
+	which also detects concurrent appenders.
+	 */
+	StatsSidecar(filePath string) (FileStats, bool, error)
+
+}
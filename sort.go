@@ -0,0 +1,423 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+/**
+KeyType defines how sort key values are compared.
+ */
+type KeyType int
+
+const (
+	StringKey KeyType = iota
+	NumericKey
+	TimeKey
+)
+
+/**
+NullOrder defines placement of missing and null values, it does not depend on the direction.
+ */
+type NullOrder int
+
+const (
+	NullsLast NullOrder = iota
+	NullsFirst
+)
+
+/**
+Single key of the sort specification.
+ */
+type SortKey struct {
+
+	/*
+	JSON field dot path or CSV column name.
+	 */
+	Field string
+
+	/*
+	Sorts in descending order.
+	 */
+	Descending bool
+
+	/*
+	Type of the values.
+	 */
+	Type KeyType
+
+	/*
+	Time layout for TimeKey, default is RFC3339.
+	 */
+	Layout string
+
+	/*
+	Placement of missing values.
+	 */
+	Nulls NullOrder
+}
+
+/**
+Ordered list of sort keys, the next key is used when values of previous keys are equal.
+Records equal by all keys keep the original order.
+ */
+type SortSpec []SortKey
+
+/**
+Compiles comparator of raw JSON records used by in-memory chunk sort and k-way merge.
+ */
+func (s SortSpec) JsonComparator() func(a, b json.RawMessage) (int, error) {
+	return func(a, b json.RawMessage) (int, error) {
+		for _, k := range s {
+			av, aok, err := jsonSortValue(a, k.Field)
+			if err != nil {
+				return 0, err
+			}
+			bv, bok, err := jsonSortValue(b, k.Field)
+			if err != nil {
+				return 0, err
+			}
+			if c, err := k.compare(av, aok, bv, bok); c != 0 || err != nil {
+				return c, err
+			}
+		}
+		return 0, nil
+	}
+}
+
+/**
+Compiles comparator of CSV records, empty values are treated as missing.
+ */
+func (s SortSpec) CsvComparator() func(a, b CsvRecord) (int, error) {
+	return func(a, b CsvRecord) (int, error) {
+		for _, k := range s {
+			av, bv := a.Field(k.Field, ""), b.Field(k.Field, "")
+			if c, err := k.compare(av, av != "", bv, bv != ""); c != 0 || err != nil {
+				return c, err
+			}
+		}
+		return 0, nil
+	}
+}
+
+/**
+Compares integers exactly when both fit in int64 or uint64, float64 loses precision above 2^53.
+ */
+func compareIntegers(a, b string) (int, bool) {
+	if x, err := strconv.ParseInt(a, 10, 64); err == nil {
+		if y, err := strconv.ParseInt(b, 10, 64); err == nil {
+			return compareInt64(x, y), true
+		}
+	}
+	if x, err := strconv.ParseUint(a, 10, 64); err == nil {
+		if y, err := strconv.ParseUint(b, 10, 64); err == nil {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func compareInt64(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func jsonSortValue(raw json.RawMessage, field string) (string, bool, error) {
+	value, ok, err := jsonField(raw, field)
+	if err != nil || !ok || string(value) == "null" {
+		return "", false, err
+	}
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s, true, nil
+	}
+	return string(value), true, nil
+}
+
+func (k SortKey) compare(a string, aok bool, b string, bok bool) (int, error) {
+	switch {
+	case !aok && !bok:
+		return 0, nil
+	case !aok || !bok:
+		c := -1
+		if !bok {
+			c = 1
+		}
+		if k.Nulls == NullsLast {
+			c = -c
+		}
+		return c, nil
+	}
+	var c int
+	switch k.Type {
+	case NumericKey:
+		if ic, ok := compareIntegers(a, b); ok {
+			c = ic
+			break
+		}
+		x, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return 0, fmt.Errorf("fs: sort key '%s': %w", k.Field, err)
+		}
+		y, err := strconv.ParseFloat(b, 64)
+		if err != nil {
+			return 0, fmt.Errorf("fs: sort key '%s': %w", k.Field, err)
+		}
+		switch {
+		case x < y:
+			c = -1
+		case x > y:
+			c = 1
+		}
+	case TimeKey:
+		layout := k.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		x, err := time.Parse(layout, a)
+		if err != nil {
+			return 0, fmt.Errorf("fs: sort key '%s': %w", k.Field, err)
+		}
+		y, err := time.Parse(layout, b)
+		if err != nil {
+			return 0, fmt.Errorf("fs: sort key '%s': %w", k.Field, err)
+		}
+		switch {
+		case x.Before(y):
+			c = -1
+		case x.After(y):
+			c = 1
+		}
+	default:
+		switch {
+		case a < b:
+			c = -1
+		case a > b:
+			c = 1
+		}
+	}
+	if k.Descending {
+		c = -c
+	}
+	return c, nil
+}
+
+/**
+Base interface for external sort of files that do not fit in memory.
+ */
+type SortService interface {
+
+	/*
+	Sorts JSON file by the specification using temp chunks and k-way merge, stable for equal records.
+	 */
+	SortJsonFile(inputFilePath, outputFilePath string, spec SortSpec) error
+
+	/*
+	Sorts CSV file by the specification, header is kept as the first row.
+	 */
+	SortCsvFile(inputFilePath, outputFilePath string, spec SortSpec) error
+
+	/*
+	Finds the first record with the key in plain JSON file sorted by key in string order, using O(log n) seeks aligned to line boundaries.
+	Inversion found near probed positions returns UnsortedFileError, compressed file returns UnsupportedError.
+	 */
+	LookupSortedJsonFile(filePath string, keyFn func(json.RawMessage) (string, error), key string) (json.RawMessage, bool, error)
+
+	/*
+	Finds all contiguous records with the key in plain JSON file sorted by key.
+	 */
+	LookupSortedJsonFileAll(filePath string, keyFn func(json.RawMessage) (string, error), key string) ([]json.RawMessage, error)
+
+	/*
+	Finds the first row with the key in the column of plain CSV file sorted by the column in string order, rows must not span lines.
+	 */
+	LookupSortedCsvFile(filePath string, column string, key string) ([]string, bool, error)
+
+	/*
+	Finds all contiguous rows with the key in the column of plain CSV file sorted by the column.
+	 */
+	LookupSortedCsvFileAll(filePath string, column string, key string) ([][]string, error)
+
+}
+
+/**
+UnsortedFileError is returned by sorted lookup when records near the offset are out of order.
+ */
+type UnsortedFileError struct {
+	File   string
+	Offset int64
+}
+
+func (e *UnsortedFileError) Error() string {
+	return fmt.Sprintf("fs: '%s' is not sorted near offset %d", e.File, e.Offset)
+}
+
+const lookupChunkSize = 4096
+
+/**
+Finds lines with the key in sorted plain content of r with the size, it backs sorted lookups of the service, name is used in errors.
+Probes of the binary search are aligned to the next line start and compared with the following line to detect inversions lazily.
+Returns the first matching line, or all contiguous matching lines if all is true, without line terminators.
+CSV callers pass section reader after the header, gzip content returns UnsupportedError.
+ */
+func LookupSortedLines(name string, r io.ReaderAt, size int64, keyFn func(line []byte) (string, error), key string, all bool) ([][]byte, error) {
+	if size >= 2 {
+		var magic [2]byte
+		if _, err := r.ReadAt(magic[:], 0); err != nil {
+			return nil, err
+		}
+		if magic[0] == 0x1f && magic[1] == 0x8b {
+			return nil, &UnsupportedError{Op: "sorted lookup", Path: name, Reason: "gzip file, build an index or recompress it as plain file"}
+		}
+	}
+	l := &lineLookup{name: name, r: r, size: size, keyFn: keyFn}
+	lo, hi := int64(0), size
+	for lo < hi {
+		probe, err := l.lineStart(lo + (hi-lo)/2)
+		if err != nil {
+			return nil, err
+		}
+		if probe >= hi {
+			probe = lo
+		}
+		k, next, err := l.checkedKey(probe)
+		if err != nil {
+			return nil, err
+		}
+		if k < key {
+			lo = next
+		} else {
+			hi = probe
+		}
+	}
+	var lines [][]byte
+	for lo < size {
+		line, next, err := l.lineAt(lo)
+		if err != nil {
+			return nil, err
+		}
+		k, err := keyFn(line)
+		if err != nil {
+			return nil, err
+		}
+		if k != key {
+			if k < key {
+				return nil, &UnsortedFileError{File: name, Offset: lo}
+			}
+			break
+		}
+		lines = append(lines, line)
+		if !all {
+			break
+		}
+		lo = next
+	}
+	return lines, nil
+}
+
+type lineLookup struct {
+	name  string
+	r     io.ReaderAt
+	size  int64
+	keyFn func(line []byte) (string, error)
+}
+
+func (l *lineLookup) read(off int64) ([]byte, error) {
+	n := int64(lookupChunkSize)
+	if off+n > l.size {
+		n = l.size - off
+	}
+	buf := make([]byte, n)
+	if _, err := l.r.ReadAt(buf, off); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf, nil
+}
+
+/*
+Gets the first line start at or after pos, size if there is none.
+ */
+func (l *lineLookup) lineStart(pos int64) (int64, error) {
+	if pos == 0 {
+		return 0, nil
+	}
+	for off := pos - 1; off < l.size; {
+		buf, err := l.read(off)
+		if err != nil {
+			return 0, err
+		}
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			return off + int64(i) + 1, nil
+		}
+		off += int64(len(buf))
+	}
+	return l.size, nil
+}
+
+/*
+Gets the line starting at off without terminator and the next line start, long lines are read in chunks.
+ */
+func (l *lineLookup) lineAt(off int64) ([]byte, int64, error) {
+	var line []byte
+	for pos := off; pos < l.size; {
+		buf, err := l.read(pos)
+		if err != nil {
+			return nil, 0, err
+		}
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			line = append(line, buf[:i]...)
+			return bytes.TrimSuffix(line, []byte{'\r'}), pos + int64(i) + 1, nil
+		}
+		line = append(line, buf...)
+		pos += int64(len(buf))
+	}
+	return bytes.TrimSuffix(line, []byte{'\r'}), l.size, nil
+}
+
+/*
+Gets key of the line at off and the next line start, the following line with lower key returns UnsortedFileError.
+ */
+func (l *lineLookup) checkedKey(off int64) (string, int64, error) {
+	line, next, err := l.lineAt(off)
+	if err != nil {
+		return "", 0, err
+	}
+	k, err := l.keyFn(line)
+	if err != nil {
+		return "", 0, err
+	}
+	if next < l.size {
+		following, _, err := l.lineAt(next)
+		if err != nil {
+			return "", 0, err
+		}
+		fk, err := l.keyFn(following)
+		if err != nil {
+			return "", 0, err
+		}
+		if fk < k {
+			return "", 0, &UnsortedFileError{File: l.name, Offset: next}
+		}
+	}
+	return k, next, nil
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sortRecord struct {
+	partner  string
+	time     time.Time
+	hasTime  bool
+	id       int64
+	original int
+}
+
+func (r sortRecord) json() json.RawMessage {
+	var parts []string
+	if r.partner != "" {
+		parts = append(parts, `"partner_id":"`+r.partner+`"`)
+	}
+	if r.hasTime {
+		parts = append(parts, `"event_time":"`+r.time.Format(time.RFC3339)+`"`)
+	}
+	parts = append(parts, `"id":`+strconv.FormatInt(r.id, 10))
+	return json.RawMessage("{" + strings.Join(parts, ",") + "}")
+}
+
+var testSortSpec = SortSpec{
+	{Field: "partner_id", Nulls: NullsFirst},
+	{Field: "event_time", Type: TimeKey, Descending: true},
+	{Field: "id", Type: NumericKey},
+}
+
+/*
+Reference order of testSortSpec on decoded records: missing partner first, then partner, time descending with missing last, id.
+ */
+func referenceCompare(a, b sortRecord) int {
+	switch {
+	case a.partner == "" && b.partner != "":
+		return -1
+	case a.partner != "" && b.partner == "":
+		return 1
+	case a.partner < b.partner:
+		return -1
+	case a.partner > b.partner:
+		return 1
+	}
+	switch {
+	case a.hasTime && !b.hasTime:
+		return -1
+	case !a.hasTime && b.hasTime:
+		return 1
+	case a.hasTime && a.time.After(b.time):
+		return -1
+	case a.hasTime && a.time.Before(b.time):
+		return 1
+	}
+	return compareInt64(a.id, b.id)
+}
+
+func randomSortRecords(r *rand.Rand, n int) []sortRecord {
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := make([]sortRecord, n)
+	for i := range records {
+		rec := sortRecord{id: r.Int63n(20) - 5, original: i}
+		if r.Intn(8) != 0 {
+			rec.partner = "p" + strconv.Itoa(r.Intn(5))
+		}
+		if r.Intn(8) != 0 {
+			rec.time, rec.hasTime = base.Add(time.Duration(r.Intn(10))*time.Hour), true
+		}
+		records[i] = rec
+	}
+	return records
+}
+
+func TestSortSpecJsonComparatorProperty(t *testing.T) {
+	cmp := testSortSpec.JsonComparator()
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		records := randomSortRecords(r, 200)
+		raws := make([]json.RawMessage, len(records))
+		for i, rec := range records {
+			raws[i] = rec.json()
+		}
+		for i := 0; i < 500; i++ {
+			a, b := r.Intn(len(records)), r.Intn(len(records))
+			c, err := cmp(raws[a], raws[b])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := referenceCompare(records[a], records[b]); c != want {
+				t.Fatalf("compare %s %s = %d, want %d", raws[a], raws[b], c, want)
+			}
+		}
+		var sortErr error
+		sort.SliceStable(records, func(i, j int) bool {
+			c, err := cmp(records[i].json(), records[j].json())
+			if err != nil {
+				sortErr = err
+			}
+			return c < 0
+		})
+		if sortErr != nil {
+			t.Fatal(sortErr)
+		}
+		for i := 1; i < len(records); i++ {
+			c := referenceCompare(records[i-1], records[i])
+			if c > 0 {
+				t.Fatalf("round %d: records %d and %d out of order", round, i-1, i)
+			}
+			if c == 0 && records[i-1].original > records[i].original {
+				t.Fatalf("round %d: equal records %d and %d lost original order", round, i-1, i)
+			}
+		}
+	}
+}
+
+func TestSortSpecCsvComparator(t *testing.T) {
+	index := map[string]int{"partner_id": 0, "event_time": 1, "id": 2}
+	row := func(values ...string) CsvRecord {
+		return memCsvRecord{values: values, index: index}
+	}
+	cmp := testSortSpec.CsvComparator()
+	cases := []struct {
+		a, b CsvRecord
+		want int
+	}{
+		{row("", "2023-01-01T00:00:00Z", "1"), row("a", "2023-01-01T00:00:00Z", "1"), -1},
+		{row("a", "2023-01-02T00:00:00Z", "9"), row("a", "2023-01-01T00:00:00Z", "1"), -1},
+		{row("a", "", "1"), row("a", "2023-01-01T00:00:00Z", "1"), 1},
+		{row("a", "2023-01-01T00:00:00Z", "10"), row("a", "2023-01-01T00:00:00Z", "9"), 1},
+		{row("a", "2023-01-01T00:00:00Z", "1"), row("a", "2023-01-01T00:00:00Z", "1"), 0},
+	}
+	for i, c := range cases {
+		got, err := cmp(c.a, c.b)
+		if err != nil || got != c.want {
+			t.Errorf("case %d: got %d, %v, want %d", i, got, err, c.want)
+		}
+	}
+}
+
+func TestSortKeyParseError(t *testing.T) {
+	cmp := SortSpec{{Field: "t", Type: TimeKey}}.JsonComparator()
+	if _, err := cmp(json.RawMessage(`{"t":"yesterday"}`), json.RawMessage(`{"t":"2023-01-01T00:00:00Z"}`)); err == nil || !strings.Contains(err.Error(), "'t'") {
+		t.Fatalf("want error naming the key, got %v", err)
+	}
+}
+
+func TestNumericKeyLargeIntegers(t *testing.T) {
+	cmp := SortSpec{{Field: "id", Type: NumericKey}}.JsonComparator()
+	ordered := []string{
+		"-9223372036854775808",
+		"-1",
+		"9007199254740992",
+		"9007199254740993",
+		"9223372036854775806",
+		"9223372036854775807",
+		"9223372036854775808",
+		"18446744073709551614",
+		"18446744073709551615",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a := json.RawMessage(`{"id":` + ordered[i] + `}`)
+			b := json.RawMessage(`{"id":"` + ordered[j] + `"}`)
+			c, err := cmp(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := compareInt64(int64(i), int64(j)); c != want {
+				t.Errorf("compare %s with %s = %d, want %d", ordered[i], ordered[j], c, want)
+			}
+		}
+	}
+}
+
+func TestLoadJsonSetKeepsLargeIntegers(t *testing.T) {
+	set := ValueSet{}
+	if err := LoadJsonSet(newMemJsonReader("{\"id\":18446744073709551615}\n{\"id\":9007199254740993}\n"), "id", set); err != nil {
+		t.Fatal(err)
+	}
+	if !set.Contains("18446744073709551615") || !set.Contains("9007199254740993") || set.Contains("9007199254740992") {
+		t.Fatalf("set %v", set)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+/**
+Default threshold of spillable buffers, 64 MB.
+ */
+const DefaultSpillThreshold = 64 * 1024 * 1024
+
+/**
+SpillBuffer is FIFO buffer that stays in memory up to the threshold and transparently spills to temp file beyond it.
+Reads consume bytes in order of writes. Not safe for concurrent use.
+ */
+type SpillBuffer struct {
+	threshold int64
+	dir       string
+	onSpill   func(size int64)
+	mem       bytes.Buffer
+	fd        *os.File
+	wOff      int64
+	rOff      int64
+	closed    bool
+}
+
+/**
+Creates spill buffer, temp file is created in dir (os.TempDir if empty) and onSpill is called once when it happens.
+ */
+func NewSpillBuffer(threshold int64, dir string, onSpill func(size int64)) *SpillBuffer {
+	return &SpillBuffer{threshold: threshold, dir: dir, onSpill: onSpill}
+}
+
+func (t *SpillBuffer) Write(p []byte) (int, error) {
+	if t.closed {
+		return 0, ErrClosed
+	}
+	if t.fd == nil && int64(t.mem.Len()+len(p)) > t.threshold {
+		if err := t.spill(); err != nil {
+			return 0, err
+		}
+	}
+	if t.fd == nil {
+		return t.mem.Write(p)
+	}
+	n, err := t.fd.WriteAt(p, t.wOff)
+	t.wOff += int64(n)
+	return n, err
+}
+
+func (t *SpillBuffer) spill() error {
+	fd, err := os.CreateTemp(t.dir, "fs-spill-*")
+	if err != nil {
+		return err
+	}
+	n, err := fd.Write(t.mem.Bytes())
+	if err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		return err
+	}
+	t.fd, t.wOff, t.rOff = fd, int64(n), 0
+	t.mem = bytes.Buffer{}
+	if t.onSpill != nil {
+		t.onSpill(t.wOff)
+	}
+	return nil
+}
+
+func (t *SpillBuffer) Read(p []byte) (int, error) {
+	if t.closed {
+		return 0, ErrClosed
+	}
+	if t.fd == nil {
+		return t.mem.Read(p)
+	}
+	if t.rOff >= t.wOff {
+		return 0, io.EOF
+	}
+	if remaining := t.wOff - t.rOff; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := t.fd.ReadAt(p, t.rOff)
+	t.rOff += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+/*
+Gets number of unread bytes.
+ */
+func (t *SpillBuffer) Len() int64 {
+	if t.fd == nil {
+		return int64(t.mem.Len())
+	}
+	return t.wOff - t.rOff
+}
+
+/*
+Checks if buffer spilled to temp file.
+ */
+func (t *SpillBuffer) Spilled() bool {
+	return t.fd != nil
+}
+
+/*
+Releases memory and removes temp file. Reads, writes and repeated Close after Close return ErrClosed.
+ */
+func (t *SpillBuffer) Close() error {
+	if t.closed {
+		return ErrClosed
+	}
+	t.closed = true
+	t.mem = bytes.Buffer{}
+	if t.fd == nil {
+		return nil
+	}
+	name := t.fd.Name()
+	err := t.fd.Close()
+	t.fd = nil
+	if rerr := os.Remove(name); err == nil {
+		err = rerr
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSpillBufferInMemory(t *testing.T) {
+	b := NewSpillBuffer(16, t.TempDir(), func(int64) { t.Fatal("spilled under threshold") })
+	defer b.Close()
+	b.Write([]byte("abcdefgh"))
+	b.Write([]byte("12345678"))
+	if b.Spilled() || b.Len() != 16 {
+		t.Fatalf("spilled %v, len %d", b.Spilled(), b.Len())
+	}
+	data, err := io.ReadAll(b)
+	if err != nil || string(data) != "abcdefgh12345678" {
+		t.Fatalf("read %q, %v", data, err)
+	}
+}
+
+func TestSpillBufferSpills(t *testing.T) {
+	dir := t.TempDir()
+	var spilled int64
+	b := NewSpillBuffer(8, dir, func(size int64) { spilled = size })
+	b.Write([]byte("abcdef"))
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(b, head); err != nil || string(head) != "ab" {
+		t.Fatalf("read %q, %v", head, err)
+	}
+	if _, err := b.Write([]byte("ghijk")); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Spilled() || spilled != 4 {
+		t.Fatalf("spilled %v with %d bytes", b.Spilled(), spilled)
+	}
+	b.Write(bytes.Repeat([]byte("z"), 100))
+	if b.Len() != 109 {
+		t.Fatalf("len %d", b.Len())
+	}
+	data, err := io.ReadAll(b)
+	if err != nil || string(data) != "cdefghijk"+string(bytes.Repeat([]byte("z"), 100)) {
+		t.Fatalf("read %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("%d temp files", len(entries))
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("temp file left after close: %v", entries)
+	}
+}
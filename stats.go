@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+/**
+Log-bucketed histogram of record sizes, bucket i counts sizes in range [2^(i-1), 2^i).
+Zero value is ready to use, not safe for concurrent use.
+ */
+type SizeHistogram struct {
+	Buckets [65]int64
+	Count   int64
+	Sum     int64
+	Min     int64
+	Max     int64
+}
+
+/*
+Adds record size to the histogram.
+ */
+func (h *SizeHistogram) Add(size int64) {
+	h.Buckets[bits.Len64(uint64(size))]++
+	if h.Count == 0 || size < h.Min {
+		h.Min = size
+	}
+	if size > h.Max {
+		h.Max = size
+	}
+	h.Count++
+	h.Sum += size
+}
+
+/*
+Gets mean size, zero if empty.
+ */
+func (h *SizeHistogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return float64(h.Sum) / float64(h.Count)
+}
+
+/*
+Estimates the size at quantile q in range [0, 1] as the upper bound of the bucket, capped by Max.
+ */
+func (h *SizeHistogram) Quantile(q float64) int64 {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.Count)))
+	var seen int64
+	for i, n := range h.Buckets {
+		seen += n
+		if seen >= rank && n > 0 {
+			if i == 0 {
+				return 0
+			}
+			upper := int64(1)<<uint(i) - 1
+			if i == 64 || upper > h.Max {
+				return h.Max
+			}
+			return upper
+		}
+	}
+	return h.Max
+}
+
+/**
+Statistics of the reader collected until Close.
+ */
+type ReaderStats struct {
+
+	/*
+	Number of records read.
+	 */
+	Records int64
+
+	/*
+	Number of records dropped by tombstone filter.
+	 */
+	Filtered int64
+
+	/*
+	Number of uncompressed bytes read.
+	 */
+	Bytes int64
+
+	/*
+	Raw record size histogram, nil unless CollectSizeStats option is enabled.
+	 */
+	Sizes *SizeHistogram
+
+	/*
+	High-water buffer size of the reader.
+	 */
+	BufferSize int
+
+	/*
+	Compression trailer was verified on Close.
+	 */
+	TrailerVerified bool
+
+	/*
+	Total number of warnings.
+	 */
+	Warnings int64
+
+	/*
+	Number of malformed lines skipped in lenient mode.
+	 */
+	Skipped int64
+
+}
+
+/**
+Statistics of the writer collected until Close.
+ */
+type WriterStats struct {
+
+	/*
+	Number of records written.
+	 */
+	Records int64
+
+	/*
+	Number of uncompressed bytes written.
+	 */
+	Bytes int64
+
+	/*
+	Final buffer size of the writer.
+	 */
+	BufferSize int
+
+	/*
+	Number of records rejected by validation rules.
+	 */
+	Rejected int64
+
+	/*
+	Total number of warnings.
+	 */
+	Warnings int64
+
+}
+
+/**
+Statistics of the bulk operation.
+ */
+type OperationStats struct {
+
+	/*
+	Number of records read.
+	 */
+	Records int64
+
+	/*
+	Number of records written.
+	 */
+	Written int64
+
+	/*
+	Number of records skipped.
+	 */
+	Skipped int64
+
+	/*
+	Number of uncompressed bytes read.
+	 */
+	Bytes int64
+
+	/*
+	First warnings and total number of warnings.
+	 */
+	Warnings      []Warning
+	TotalWarnings int64
+
+	/*
+	Duration of the operation.
+	 */
+	Duration time.Duration
+
+	/*
+	Join concatenated compressed members without recompression.
+	 */
+	FastPath bool
+
+}
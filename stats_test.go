@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	var h SizeHistogram
+	if h.Mean() != 0 || h.Quantile(0.5) != 0 {
+		t.Fatal("empty histogram is not zero")
+	}
+	for _, size := range []int64{0, 1, 3, 100, 100, 100, 100, 100, 100, 5000} {
+		h.Add(size)
+	}
+	if h.Count != 10 || h.Min != 0 || h.Max != 5000 || h.Buckets[0] != 1 || h.Buckets[7] != 6 {
+		t.Fatalf("unexpected %+v", h)
+	}
+	if h.Mean() != 560.4 {
+		t.Fatalf("mean %v", h.Mean())
+	}
+	for q, want := range map[float64]int64{0: 0, 0.1: 0, 0.2: 1, 0.5: 127, 0.9: 127, 1: 5000} {
+		if got := h.Quantile(q); got != want {
+			t.Errorf("quantile %v = %d, want %d", q, got, want)
+		}
+	}
+}
+
+func TestSizeHistogramQuantileCappedByMax(t *testing.T) {
+	var h SizeHistogram
+	h.Add(70)
+	if got := h.Quantile(0.99); got != 70 {
+		t.Fatalf("quantile above max: %d", got)
+	}
+}
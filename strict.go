@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "fmt"
+
+/**
+Violation is the silent fallback behavior that strict mode turns in to error.
+ */
+type Violation int
+
+const (
+	/*
+	Unknown file extension falls back to no compression and no format.
+	 */
+	UnknownExtensionViolation Violation = iota
+
+	/*
+	CSV row has different number of values than the header.
+	 */
+	RaggedRowViolation
+
+	/*
+	Column referenced by processor or rule is not in the header.
+	 */
+	UnknownColumnViolation
+
+	/*
+	Missing JSON key or CSV column returns default value.
+	 */
+	MissingKeyViolation
+
+	/*
+	JSON object has duplicate keys, strict mode sets ErrorOnDuplicate.
+	 */
+	DuplicateKeyViolation
+
+	/*
+	Service Close flushes and closes tracked writers that are still open.
+	 */
+	OpenWriterViolation
+)
+
+func (v Violation) String() string {
+	switch v {
+	case UnknownExtensionViolation:
+		return "unknown extension"
+	case RaggedRowViolation:
+		return "ragged row"
+	case UnknownColumnViolation:
+		return "unknown column"
+	case MissingKeyViolation:
+		return "missing key"
+	case DuplicateKeyViolation:
+		return "duplicate key"
+	case OpenWriterViolation:
+		return "open writer"
+	default:
+		return "unknown violation"
+	}
+}
+
+/**
+Gets all violations controlled by strict mode.
+ */
+func StrictViolations() []Violation {
+	return []Violation{
+		UnknownExtensionViolation,
+		RaggedRowViolation,
+		UnknownColumnViolation,
+		MissingKeyViolation,
+		DuplicateKeyViolation,
+		OpenWriterViolation,
+	}
+}
+
+/**
+StrictError is returned instead of the fallback behavior when violation is strict.
+ */
+type StrictError struct {
+	Violation Violation
+	Detail    string
+}
+
+func (e *StrictError) Error() string {
+	return fmt.Sprintf("fs: strict %s: %s", e.Violation, e.Detail)
+}
+
+/**
+Base interface of strictness settings of the service.
+ */
+type StrictPolicy interface {
+
+	/*
+	Enables or disables all strict violations.
+	 */
+	SetStrictMode(enabled bool)
+
+	/*
+	Enables or disables single violation.
+	 */
+	SetStrict(violation Violation, enabled bool)
+
+	/*
+	Checks if violation returns StrictError.
+	 */
+	IsStrict(violation Violation) bool
+
+}
@@ -0,0 +1,27 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"testing"
+)
+
+func TestStrictViolationsNamed(t *testing.T) {
+	names := make(map[string]Violation)
+	for _, v := range StrictViolations() {
+		name := v.String()
+		if name == "unknown violation" {
+			t.Errorf("violation %d has no name", v)
+		}
+		if prev, ok := names[name]; ok {
+			t.Errorf("violations %d and %d share name '%s'", prev, v, name)
+		}
+		names[name] = v
+	}
+	if len(names) != int(OpenWriterViolation)+1 {
+		t.Fatalf("strict mode controls %d of %d violations", len(names), OpenWriterViolation+1)
+	}
+}
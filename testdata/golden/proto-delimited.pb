@@ -0,0 +1,6 @@
+
+
+1|alpha|10
+2|comma, "quote"	tab|-1%
+#3|юникод <&>|9007199254740993
+4||0
\ No newline at end of file
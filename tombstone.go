@@ -0,0 +1,29 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+)
+
+/**
+Base interface for compaction of files with tombstone records.
+Compaction makes two passes, the first one collects tombstoned keys, so memory is bounded by the number of distinct tombstoned keys.
+ */
+type CompactionService interface {
+
+	/*
+	Removes tombstones and all records before the tombstone with the same key. Records after the tombstone survive.
+	 */
+	CompactJsonFileWithTombstones(inputPath, outputPath string, keyFn func(json.RawMessage) (string, error), isTombstone func(json.RawMessage) bool) error
+
+	/*
+	Removes tombstones and all records before the tombstone with the same key, holder is used to unmarshal records.
+	 */
+	CompactProtoFileWithTombstones(inputPath, outputPath string, holder proto.Message, keyFn func(proto.Message) (string, error), isTombstone func(proto.Message) bool) error
+
+}
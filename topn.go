@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+)
+
+/**
+Base interface for bounded-memory top-N selection in a single streaming pass.
+Result is the first n records in order of less, so pass reversed comparator to get the largest ones.
+Memory is O(n) regardless of the file size, ties are broken by input order, comparator errors are returned as RecordError.
+ */
+type TopNService interface {
+
+	/*
+	Selects top n JSON records.
+	 */
+	TopNJsonFile(inputPath string, n int, less func(a, b json.RawMessage) (bool, error)) ([]json.RawMessage, error)
+
+	/*
+	Selects top n JSON records and writes them to the output file, used when n is large.
+	 */
+	TopNJsonFileTo(inputPath string, outputPath string, n int, less func(a, b json.RawMessage) (bool, error)) error
+
+	/*
+	Selects top n CSV records.
+	 */
+	TopNCsvFile(inputPath string, n int, less func(a, b CsvRecord) (bool, error)) ([]CsvRecord, error)
+
+	/*
+	Selects top n CSV records ordered by the column specification.
+	 */
+	TopNCsvFileBy(inputPath string, n int, spec SortSpec) ([]CsvRecord, error)
+
+	/*
+	Selects top n protobuf records, factory creates new holder for every kept record.
+	 */
+	TopNProtoFile(inputPath string, n int, factory func() proto.Message, less func(a, b proto.Message) (bool, error)) ([]proto.Message, error)
+
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "errors"
+
+/**
+Returned by writers of the transaction after Commit or Rollback.
+ */
+var ErrTxnDone = errors.New("fs: transaction is already committed or rolled back")
+
+/**
+Suffix of the transaction journal file written in to the directory of the first output before renames.
+ */
+const TxnJournalSuffix = ".txn.journal"
+
+/**
+Txn stages several outputs as temp files and publishes them all or none.
+ */
+type Txn interface {
+
+	/*
+	Creates staged JSON file, visible at file path only after Commit.
+	 */
+	NewJsonFile(filePath string) (JsonWriter, error)
+
+	/*
+	Creates staged protofile, visible at file path only after Commit.
+	 */
+	NewProtoFile(filePath string) (ProtoWriter, error)
+
+	/*
+	Creates staged CSV file, visible at file path only after Commit.
+	 */
+	NewCsvFile(filePath string, valueProcessors ...CsvValueProcessor) (CsvWriter, error)
+
+	/*
+	Closes open writers, writes journal and renames all staged files in order of creation, then removes the journal.
+	 */
+	Commit() error
+
+	/*
+	Closes open writers and removes all staged files.
+	 */
+	Rollback() error
+
+}
+
+/**
+Base interface for transactional output of several files.
+ */
+type TransactionService interface {
+
+	/*
+	Creates new transaction.
+	 */
+	NewTransaction() (Txn, error)
+
+	/*
+	Finds journals in directory left by crashed commits and completes their renames.
+	 */
+	RecoverTransaction(dir string) error
+
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"reflect"
+)
+
+/**
+Creates holder for the reader. For pointer types like `*pb.Message` it allocates the message, so proto holders go to the reader as proto.Message.
+ */
+func newHolder[T any]() (*T, interface{}) {
+	item := new(T)
+	if t := reflect.TypeOf(item).Elem(); t.Kind() == reflect.Ptr {
+		p := reflect.New(t.Elem())
+		reflect.ValueOf(item).Elem().Set(p)
+		return item, p.Interface()
+	}
+	return item, item
+}
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+/**
+Reads all records of JSON reader in to the typed slice, stops on io.EOF. Proto messages are read with unmarshal options of the reader.
+Proto message must be a pointer type like `*pb.Message`, value type would copy internal state of the message and returns error, see ReadAllProtoJson.
+ */
+func ReadAllJson[T any](r JsonReader) ([]T, error) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(protoMessageType) {
+		return nil, fmt.Errorf("fs: proto message type %s must be read as pointer, use ReadAllProtoJson", t)
+	}
+	var list []T
+	for {
+		item, holder := newHolder[T]()
+		if err := r.Read(holder); err != nil {
+			if err == io.EOF {
+				return list, nil
+			}
+			return list, err
+		}
+		list = append(list, *item)
+	}
+}
+
+/**
+Reads all proto records of JSON reader, type parameter is the message struct, e.g. `ReadAllProtoJson[pb.Message]`, and records are its pointers.
+ */
+func ReadAllProtoJson[T any, P interface {
+	*T
+	proto.Message
+}](r JsonReader) ([]P, error) {
+	var list []P
+	for {
+		m := P(new(T))
+		if err := r.Read(m); err != nil {
+			if err == io.EOF {
+				return list, nil
+			}
+			return list, err
+		}
+		list = append(list, m)
+	}
+}
+
+/**
+Writes all items to JSON writer, stops on the first error.
+ */
+func WriteAllJson[T any](w JsonWriter, items []T) error {
+	for _, item := range items {
+		if err := w.Write(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+TypedJsonWriter is JSON writer of a single type.
+ */
+type TypedJsonWriter[T any] struct {
+	JsonWriter
+}
+
+/**
+Wraps JSON writer in to typed writer.
+ */
+func NewTypedJsonWriter[T any](w JsonWriter) TypedJsonWriter[T] {
+	return TypedJsonWriter[T]{JsonWriter: w}
+}
+
+/*
+Writes typed item, proto messages are marshaled with marshal options of the writer.
+ */
+func (w TypedJsonWriter[T]) Write(item T) error {
+	return w.JsonWriter.Write(item)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+)
+
+const typedRecords = "{\"value\":\"a\"}\n{\"value\":\"b\"}\n"
+
+func TestReadAllJsonStruct(t *testing.T) {
+	type item struct {
+		Value string `json:"value"`
+	}
+	list, err := ReadAllJson[item](newMemJsonReader(typedRecords))
+	if err != nil || len(list) != 2 || list[1].Value != "b" {
+		t.Fatalf("list %v, err %v", list, err)
+	}
+}
+
+func TestReadAllJsonProtoPointer(t *testing.T) {
+	list, err := ReadAllJson[*wrapperspb.StringValue](newMemJsonReader(typedRecords))
+	if err != nil || len(list) != 2 || list[0].Value != "a" || list[0] == list[1] {
+		t.Fatalf("list %v, err %v", list, err)
+	}
+}
+
+func TestReadAllJsonProtoValueRejected(t *testing.T) {
+	if _, err := ReadAllJson[wrapperspb.StringValue](newMemJsonReader(typedRecords)); err == nil {
+		t.Fatal("proto value type accepted")
+	}
+}
+
+func TestReadAllProtoJson(t *testing.T) {
+	list, err := ReadAllProtoJson[wrapperspb.StringValue](newMemJsonReader(typedRecords))
+	if err != nil || len(list) != 2 || list[1].GetValue() != "b" {
+		t.Fatalf("list %v, err %v", list, err)
+	}
+}
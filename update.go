@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import "strings"
+
+/**
+Default separator of composite key values.
+ */
+const KeySeparator = "\x1f"
+
+/**
+Joins key column values in to the composite key used by update maps.
+ */
+func CompositeKey(values ...string) string {
+	return strings.Join(values, KeySeparator)
+}
+
+/**
+Options of CSV column update.
+ */
+type UpdateOptions struct {
+
+	/*
+	Rewrites input file using atomic temp-and-rename, output path is ignored.
+	 */
+	InPlace bool
+
+	/*
+	Appends rows for update keys not found in the file.
+	 */
+	AppendUnmatched bool
+
+	/*
+	Values of appended rows by column name, key and target columns are set from the update.
+	 */
+	Template map[string]string
+}
+
+/**
+Result of CSV column update.
+ */
+type UpdateReport struct {
+
+	/*
+	Number of rows where target column was replaced.
+	 */
+	UpdatedRows int64
+
+	/*
+	Update keys found in the file.
+	 */
+	MatchedKeys int
+
+	/*
+	Update keys not found in the file.
+	 */
+	UnmatchedKeys []string
+
+	/*
+	Number of rows appended for unmatched keys.
+	 */
+	AppendedRows int64
+}
+
+/**
+Base interface for partial update of CSV files.
+ */
+type CsvUpdateService interface {
+
+	/*
+	Streams the file and replaces target column value of rows which composite key is in updates.
+	Keys are built by CompositeKey from values after read processors, so they must match processed values exactly.
+	Written values go through write processors the same way.
+	 */
+	UpdateCsvColumn(inputPath, outputPath string, keyColumns []string, targetColumn string, updates map[string]string, opts UpdateOptions, valueProcessors ...CsvValueProcessor) (UpdateReport, error)
+
+}
@@ -0,0 +1,418 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+/**
+Set of allowed values used by foreign key rules.
+ */
+type Set interface {
+
+	/*
+	Checks if value is in the set.
+	 */
+	Contains(value string) bool
+
+}
+
+/**
+Set that could be filled by loaders.
+ */
+type SetBuilder interface {
+	Set
+
+	/*
+	Adds value to the set.
+	 */
+	Add(value string)
+
+}
+
+/**
+Exact in-memory set of values.
+ */
+type ValueSet map[string]struct{}
+
+func (s ValueSet) Contains(value string) bool {
+	_, ok := s[value]
+	return ok
+}
+
+func (s ValueSet) Add(value string) {
+	s[value] = struct{}{}
+}
+
+/**
+Bloom filter set for huge reference sets with bounded memory.
+Contains never returns false for added value, but returns true for missing value with the configured false positive rate,
+so foreign key rule backed by it could miss a violation and never reports a false one.
+ */
+type BloomSet struct {
+	bits []uint64
+	k    uint32
+}
+
+/**
+Creates bloom filter sized for expected number of values and false positive rate, rate must be in (0, 1).
+ */
+func NewBloomSet(expected int, fpRate float64) (*BloomSet, error) {
+	if !(fpRate > 0 && fpRate < 1) {
+		return nil, fmt.Errorf("fs: bloom false positive rate %v is not in (0, 1)", fpRate)
+	}
+	if expected < 1 {
+		expected = 1
+	}
+	m := math.Ceil(-float64(expected) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := uint32(math.Max(1, math.Round(m/float64(expected)*math.Ln2)))
+	return &BloomSet{bits: make([]uint64, int(m)/64+1), k: k}, nil
+}
+
+func (s *BloomSet) hashes(value string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	sum := h.Sum64()
+	return sum, bits.RotateLeft64(sum, 32) | 1
+}
+
+func (s *BloomSet) Add(value string) {
+	h1, h2 := s.hashes(value)
+	n := uint64(len(s.bits)) * 64
+	for i := uint64(0); i < uint64(s.k); i++ {
+		j := (h1 + i*h2) % n
+		s.bits[j/64] |= 1 << (j % 64)
+	}
+}
+
+func (s *BloomSet) Contains(value string) bool {
+	h1, h2 := s.hashes(value)
+	n := uint64(len(s.bits)) * 64
+	for i := uint64(0); i < uint64(s.k); i++ {
+		j := (h1 + i*h2) % n
+		if s.bits[j/64]&(1<<(j%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+Loads values of CSV column in to the set.
+ */
+func LoadCsvSet(r CsvReader, column string, into SetBuilder) error {
+	file, err := r.ReadHeader()
+	if err != nil {
+		return err
+	}
+	for {
+		record, err := file.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		into.Add(record.Field(column, ""))
+	}
+}
+
+/**
+Loads values of JSON field by dot path in to the set, records without field are skipped.
+ */
+func LoadJsonSet(r JsonReader, field string, into SetBuilder) error {
+	for {
+		raw, err := r.ReadRaw()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value, ok, err := jsonSortValue(raw, field)
+		if err != nil {
+			return err
+		}
+		if ok {
+			into.Add(value)
+		}
+	}
+}
+
+/**
+RuleKind is the kind of validation rule.
+ */
+type RuleKind int
+
+const (
+	UniqueRule RuleKind = iota
+	ForeignKeyRule
+	NonEmptyRule
+)
+
+func (k RuleKind) String() string {
+	switch k {
+	case UniqueRule:
+		return "unique"
+	case ForeignKeyRule:
+		return "foreign key"
+	default:
+		return "non empty"
+	}
+}
+
+/**
+Validation rule of the validating writer, columns are CSV column names or JSON dot paths.
+ */
+type Rule struct {
+	Kind    RuleKind
+	Columns []string
+	Allowed Set
+}
+
+/**
+Composite value of columns must be unique across written records, keys are kept in memory.
+ */
+func UniqueColumns(columns []string) Rule {
+	return Rule{Kind: UniqueRule, Columns: columns}
+}
+
+/**
+Value of the column must be in the allowed set.
+ */
+func ForeignKey(column string, allowedValues Set) Rule {
+	return Rule{Kind: ForeignKeyRule, Columns: []string{column}, Allowed: allowedValues}
+}
+
+/**
+Values of the columns must not be empty.
+ */
+func NonEmpty(columns []string) Rule {
+	return Rule{Kind: NonEmptyRule, Columns: columns}
+}
+
+/**
+CsvRowError is returned by validating writer when record violates the rule, row is 1-based record number.
+ */
+type CsvRowError struct {
+	Row    int64
+	Rule   RuleKind
+	Column string
+	Values []string
+}
+
+func (e *CsvRowError) Error() string {
+	return fmt.Sprintf("fs: row %d violates %s rule on '%s'", e.Row, e.Rule, e.Column)
+}
+
+type validator struct {
+	rules  []Rule
+	unique []map[string]struct{}
+	row    int64
+}
+
+func newValidator(rules []Rule) *validator {
+	v := &validator{rules: rules, unique: make([]map[string]struct{}, len(rules))}
+	for i, r := range rules {
+		if r.Kind == UniqueRule {
+			v.unique[i] = make(map[string]struct{})
+		}
+	}
+	return v
+}
+
+/*
+Checks all rules and records unique keys only for the accepted row, so rejected rows never hide later duplicates.
+ */
+func (v *validator) check(field func(column string) string, values []string) error {
+	v.row++
+	keys := make([]string, len(v.rules))
+	for i, r := range v.rules {
+		switch r.Kind {
+		case UniqueRule:
+			parts := make([]string, len(r.Columns))
+			for j, c := range r.Columns {
+				parts[j] = field(c)
+			}
+			keys[i] = strings.Join(parts, "\x00")
+			if _, ok := v.unique[i][keys[i]]; ok {
+				return &CsvRowError{Row: v.row, Rule: r.Kind, Column: strings.Join(r.Columns, ","), Values: values}
+			}
+		case ForeignKeyRule:
+			if !r.Allowed.Contains(field(r.Columns[0])) {
+				return &CsvRowError{Row: v.row, Rule: r.Kind, Column: r.Columns[0], Values: values}
+			}
+		case NonEmptyRule:
+			for _, c := range r.Columns {
+				if field(c) == "" {
+					return &CsvRowError{Row: v.row, Rule: r.Kind, Column: c, Values: values}
+				}
+			}
+		}
+	}
+	for i, r := range v.rules {
+		if r.Kind == UniqueRule {
+			v.unique[i][keys[i]] = struct{}{}
+		}
+	}
+	return nil
+}
+
+type validatingCsvWriter struct {
+	CsvWriter
+	index      map[string]int
+	quarantine CsvWriter
+	v          *validator
+	rejected   int64
+	closed     bool
+}
+
+/**
+Wraps CSV writer with validation rules, header gives column positions and is not written by the wrapper.
+Rows violating rules fail Write with CsvRowError, or are written to quarantine writer if it is not nil. Rejected rows are counted in Stats.
+ */
+func NewValidatingCsvWriter(w CsvWriter, header []string, quarantine CsvWriter, rules ...Rule) CsvWriter {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+	return &validatingCsvWriter{CsvWriter: w, index: index, quarantine: quarantine, v: newValidator(rules)}
+}
+
+func (t *validatingCsvWriter) Write(values ...string) error {
+	if t.closed {
+		return ErrClosed
+	}
+	err := t.v.check(func(column string) string {
+		if i, ok := t.index[column]; ok && i < len(values) {
+			return values[i]
+		}
+		return ""
+	}, values)
+	if err == nil {
+		return t.CsvWriter.Write(values...)
+	}
+	t.rejected++
+	if t.quarantine != nil {
+		return t.quarantine.Write(values...)
+	}
+	return err
+}
+
+/*
+Closes the writer, quarantine writer is owned by the caller. Rows written after Close are not validated and return ErrClosed.
+ */
+func (t *validatingCsvWriter) Close() error {
+	if t.closed {
+		return ErrClosed
+	}
+	t.closed = true
+	return t.CsvWriter.Close()
+}
+
+func (t *validatingCsvWriter) Stats() WriterStats {
+	stats := t.CsvWriter.Stats()
+	stats.Rejected = t.rejected
+	return stats
+}
+
+type validatingJsonWriter struct {
+	JsonWriter
+	quarantine JsonWriter
+	v          *validator
+	rejected   int64
+	closed     bool
+}
+
+/**
+Wraps JSON writer with validation rules on dot paths, missing fields are empty values.
+Records violating rules fail Write with CsvRowError, or are written to quarantine writer if it is not nil. Rejected records are counted in Stats.
+ */
+func NewValidatingJsonWriter(w JsonWriter, quarantine JsonWriter, rules ...Rule) JsonWriter {
+	return &validatingJsonWriter{JsonWriter: w, quarantine: quarantine, v: newValidator(rules)}
+}
+
+func (t *validatingJsonWriter) WriteRaw(message json.RawMessage) error {
+	if t.closed {
+		return ErrClosed
+	}
+	var fieldErr error
+	err := t.v.check(func(path string) string {
+		value, _, err := jsonSortValue(message, path)
+		if err != nil && fieldErr == nil {
+			fieldErr = err
+		}
+		return value
+	}, nil)
+	if fieldErr != nil {
+		return fieldErr
+	}
+	if err == nil {
+		return t.JsonWriter.WriteRaw(message)
+	}
+	t.rejected++
+	if t.quarantine != nil {
+		return t.quarantine.WriteRaw(message)
+	}
+	return err
+}
+
+func (t *validatingJsonWriter) Write(object interface{}) error {
+	var data []byte
+	var err error
+	if m, ok := object.(proto.Message); ok {
+		data, err = t.Options().MarshalOptions.Marshal(m)
+	} else {
+		data, err = json.Marshal(object)
+	}
+	if err != nil {
+		return err
+	}
+	return t.WriteRaw(data)
+}
+
+func (t *validatingJsonWriter) WriteRawAll(messages []json.RawMessage) error {
+	for i, message := range messages {
+		if err := t.WriteRaw(message); err != nil {
+			return &BatchWriteError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (t *validatingJsonWriter) WriteAll(objects []interface{}) error {
+	for i, object := range objects {
+		if err := t.Write(object); err != nil {
+			return &BatchWriteError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+/*
+Closes the writer, quarantine writer is owned by the caller. Records written after Close are not validated and return ErrClosed.
+ */
+func (t *validatingJsonWriter) Close() error {
+	if t.closed {
+		return ErrClosed
+	}
+	t.closed = true
+	return t.JsonWriter.Close()
+}
+
+func (t *validatingJsonWriter) Stats() WriterStats {
+	stats := t.JsonWriter.Stats()
+	stats.Rejected = t.rejected
+	return stats
+}
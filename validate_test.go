@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestRejectedRowKeepsUniqueKeyFree(t *testing.T) {
+	out := &memCsvWriter{}
+	allowed := ValueSet{"us": {}}
+	w := NewValidatingCsvWriter(out, []string{"id", "country"}, nil, UniqueColumns([]string{"id"}), ForeignKey("country", allowed))
+	var rowErr *CsvRowError
+	if err := w.Write("1", "xx"); !errors.As(err, &rowErr) || rowErr.Rule != ForeignKeyRule {
+		t.Fatalf("want foreign key violation, got %v", err)
+	}
+	if err := w.Write("1", "us"); err != nil {
+		t.Fatalf("key of rejected row poisoned the unique set: %v", err)
+	}
+	if err := w.Write("1", "us"); !errors.As(err, &rowErr) || rowErr.Rule != UniqueRule || rowErr.Row != 3 {
+		t.Fatalf("want unique violation at row 3, got %v", err)
+	}
+	if len(out.rows) != 1 || w.Stats().Rejected != 2 {
+		t.Fatalf("written %d rows, rejected %d", len(out.rows), w.Stats().Rejected)
+	}
+}
+
+func TestRejectedJsonRecordKeepsUniqueKeyFree(t *testing.T) {
+	out := &memJsonWriter{}
+	quarantine := &memJsonWriter{}
+	w := NewValidatingJsonWriter(out, quarantine, UniqueColumns([]string{"id"}), NonEmpty([]string{"name"}))
+	for _, rec := range []string{`{"id":"1"}`, `{"id":"1","name":"a"}`, `{"id":"1","name":"b"}`} {
+		if err := w.WriteRaw([]byte(rec)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(out.records) != 1 || string(out.records[0]) != `{"id":"1","name":"a"}` || len(quarantine.records) != 2 {
+		t.Fatalf("written %q, quarantined %q", out.records, quarantine.records)
+	}
+}
+
+func TestBloomSetRate(t *testing.T) {
+	for _, rate := range []float64{0, 1, -0.5, 2} {
+		if _, err := NewBloomSet(10, rate); err == nil {
+			t.Errorf("rate %v accepted", rate)
+		}
+	}
+	s, err := NewBloomSet(10000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10000; i++ {
+		s.Add(strconv.Itoa(i))
+	}
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if !s.Contains(strconv.Itoa(i)) {
+			t.Fatalf("false negative for %d", i)
+		}
+		if s.Contains("missing-" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("%d false positives of 10000 for rate 0.01", falsePositives)
+	}
+}
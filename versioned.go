@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/**
+Handler key in VersionedJsonReader used for records with unknown or missing version.
+ */
+const DefaultVersion = "*"
+
+/**
+UnknownVersionError is returned by VersionedJsonReader if there is no handler for the record version.
+ */
+type UnknownVersionError struct {
+	Line    int64
+	Version string
+}
+
+func (e *UnknownVersionError) Error() string {
+	return fmt.Sprintf("fs: unknown schema version '%s' at line %d", e.Version, e.Line)
+}
+
+/**
+JsonWriteHook modifies raw JSON record before it is written by JsonWriter.
+ */
+type JsonWriteHook func(json.RawMessage) (json.RawMessage, error)
+
+/**
+Reads the whole stream dispatching each record to the handler of its version.
+Version is extracted from the field, that could be nested one level deep via dot path, e.g. `meta.version`.
+Records with unknown version go to the DefaultVersion handler, or UnknownVersionError is returned if it is not set.
+Only the version field is scanned, lines in errors are reader positions, so skipped blank lines are counted.
+Panic in the handler is returned as CallbackPanicError with the line as index.
+ */
+func VersionedJsonReader(r JsonReader, field string, handlers map[string]func(json.RawMessage) error) error {
+	for {
+		raw, err := r.ReadRaw()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := r.Position()
+		value, _, err := peekJsonField(raw, field)
+		if err != nil {
+			return fmt.Errorf("fs: line %d: %w", line, err)
+		}
+		version := string(value)
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			version = s
+		}
+		handler, ok := handlers[version]
+		if !ok {
+			handler, ok = handlers[DefaultVersion]
+		}
+		if !ok {
+			return &UnknownVersionError{Line: line, Version: version}
+		}
+		if err := SafeCallback("VersionedJsonReader", int(line), func() error { return handler(raw) }); err != nil {
+			return err
+		}
+	}
+}
+
+/**
+Creates write hook that injects or overwrites version field of every record, field could be a dot path.
+ */
+func VersionStamp(field, version string) JsonWriteHook {
+	value, _ := json.Marshal(version)
+	return func(raw json.RawMessage) (json.RawMessage, error) {
+		return setJsonField(raw, field, value)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestVersionedJsonReader(t *testing.T) {
+	r := newMemJsonReader(`{"v":"1","id":1}
+
+{"id":2,"meta":{"x":[1,{"v":"9"}]},"v":2}
+{"id":3,"v":"1"}
+{"id":4}
+`)
+	got := make(map[string][]string)
+	handler := func(version string) func(json.RawMessage) error {
+		return func(raw json.RawMessage) error {
+			got[version] = append(got[version], string(raw))
+			return nil
+		}
+	}
+	err := VersionedJsonReader(r, "v", map[string]func(json.RawMessage) error{
+		"1":            handler("1"),
+		"2":            handler("2"),
+		DefaultVersion: handler(DefaultVersion),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got["1"]) != 2 || len(got["2"]) != 1 || len(got[DefaultVersion]) != 1 {
+		t.Fatalf("dispatched %v", got)
+	}
+}
+
+func TestVersionedJsonReaderLine(t *testing.T) {
+	r := newMemJsonReader("{\"v\":\"1\"}\n\n\n{\"v\":\"3\"}\n")
+	err := VersionedJsonReader(r, "v", map[string]func(json.RawMessage) error{
+		"1": func(json.RawMessage) error { return nil },
+	})
+	var versionErr *UnknownVersionError
+	if !errors.As(err, &versionErr) || versionErr.Line != 4 || versionErr.Version != "3" {
+		t.Fatalf("want unknown version 3 at line 4, got %v", err)
+	}
+}
+
+func TestVersionedJsonReaderNested(t *testing.T) {
+	r := newMemJsonReader(`{"meta":{"name":"a\"}","version":"2"}}`)
+	var seen int
+	err := VersionedJsonReader(r, "meta.version", map[string]func(json.RawMessage) error{
+		"2": func(json.RawMessage) error { seen++; return nil },
+	})
+	if err != nil || seen != 1 {
+		t.Fatalf("seen %d, err %v", seen, err)
+	}
+}
+
+func TestPeekJsonField(t *testing.T) {
+	for _, c := range []struct {
+		raw, path, value string
+		found            bool
+	}{
+		{`{"a":1,"b":"x"}`, "b", `"x"`, true},
+		{` { "a" : [1, "]", {"b":2}] , "b" : true } `, "b", `true`, true},
+		{`{"a":5}`, "a", `5`, true},
+		{`{"a":{"b":{"c":null}}}`, "a.b.c", `null`, true},
+		{`{"a":1}`, "b", ``, false},
+		{`{}`, "a", ``, false},
+	} {
+		value, found, err := peekJsonField([]byte(c.raw), c.path)
+		if err != nil || found != c.found || string(value) != c.value {
+			t.Errorf("peek %s in %s is %s, %v, %v", c.path, c.raw, value, found, err)
+		}
+	}
+	for _, raw := range []string{`[1]`, `{"a"`, `{"a":}`, `{"a":"x`, `{"a":1 "b":2}`} {
+		if _, _, err := peekJsonField([]byte(raw), "b"); err == nil {
+			t.Errorf("malformed %s accepted", raw)
+		}
+	}
+}
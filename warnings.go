@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"fmt"
+	"sync"
+)
+
+/**
+Default number of warnings kept by readers and writers, the rest is only counted.
+ */
+const DefaultMaxWarnings = 100
+
+/**
+Warning about the exceeded soft limit in WarnOnly mode.
+ */
+type Warning struct {
+
+	/*
+	Name of the limit, e.g. `max record size`.
+	 */
+	Limit string
+
+	/*
+	File and 1-based line or record number.
+	 */
+	File string
+	Line int64
+
+	/*
+	Measured value and configured limit.
+	 */
+	Value int64
+	Max   int64
+
+	/*
+	Offending record was skipped instead of processed.
+	 */
+	Skipped bool
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("fs: %s:%d: %s %d exceeds %d", w.File, w.Line, w.Limit, w.Value, w.Max)
+}
+
+/**
+WarningList keeps the first N warnings and the total count, safe for concurrent use.
+ */
+type WarningList struct {
+	mu    sync.Mutex
+	max   int
+	items []Warning
+	total int64
+}
+
+/**
+Creates warning list that keeps at most max warnings.
+ */
+func NewWarningList(max int) *WarningList {
+	return &WarningList{max: max}
+}
+
+/*
+Adds warning, it is kept only if the list is not full.
+ */
+func (l *WarningList) Add(w Warning) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total++
+	if len(l.items) < l.max {
+		l.items = append(l.items, w)
+	}
+}
+
+/*
+Gets kept warnings.
+ */
+func (l *WarningList) Items() []Warning {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Warning(nil), l.items...)
+}
+
+/*
+Gets total number of added warnings.
+ */
+func (l *WarningList) Total() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.total
+}
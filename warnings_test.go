@@ -0,0 +1,41 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestWarningListKeepsFirst(t *testing.T) {
+	l := NewWarningList(3)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				l.Add(Warning{Limit: "max record size", Line: int64(i)})
+			}
+		}()
+	}
+	wg.Wait()
+	items := l.Items()
+	if len(items) != 3 || l.Total() != 200 {
+		t.Fatalf("kept %d, total %d", len(items), l.Total())
+	}
+	items[0].Line = -1
+	if l.Items()[0].Line == -1 {
+		t.Fatal("items share the list storage")
+	}
+}
+
+func TestWarningString(t *testing.T) {
+	w := Warning{Limit: "max record size", File: "a.json", Line: 7, Value: 2048, Max: 1024}
+	if got := w.String(); got != "fs: a.json:7: max record size 2048 exceeds 1024" {
+		t.Fatalf("got %s", got)
+	}
+}
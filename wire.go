@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"unicode"
+	"unicode/utf8"
+)
+
+/**
+Maximum depth of nested messages decoded without schema.
+ */
+const maxWireDepth = 32
+
+var errWireDepth = errors.New("fs: nested message is too deep")
+
+/**
+Decodes protobuf payload without schema in to generic tree keyed by field numbers, e.g. `{"1": 123, "2": {"1": "abc"}}`.
+Conventions: varint and fixed values are numbers; field seen more than once becomes an array;
+length-delimited value is a nested object if it parses as a message, a string if it is printable UTF-8, otherwise `{"bytes": "<base64>"}`.
+Short printable strings could parse as a message too, then they are shown as nested objects. Deprecated groups are skipped.
+ */
+func DecodeWireMessage(payload []byte) (map[string]interface{}, error) {
+	return decodeWire(payload, 0)
+}
+
+func decodeWire(b []byte, depth int) (map[string]interface{}, error) {
+	if depth > maxWireDepth {
+		return nil, errWireDepth
+	}
+	tree := make(map[string]interface{})
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		var value interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, b = v, b[n:]
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, b = v, b[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, b = v, b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, b = decodeWireBytes(v, depth), b[n:]
+		case protowire.StartGroupType:
+			_, n := protowire.ConsumeGroup(num, b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		default:
+			return nil, fmt.Errorf("fs: unsupported wire type %d of field %d", typ, num)
+		}
+		key := fmt.Sprint(int32(num))
+		switch prev := tree[key].(type) {
+		case nil:
+			tree[key] = value
+		case []interface{}:
+			tree[key] = append(prev, value)
+		default:
+			tree[key] = []interface{}{prev, value}
+		}
+	}
+	return tree, nil
+}
+
+func decodeWireBytes(v []byte, depth int) interface{} {
+	if len(v) > 0 {
+		if nested, err := decodeWire(v, depth+1); err == nil && len(nested) > 0 {
+			return nested
+		}
+	}
+	if isPrintable(v) {
+		return string(v)
+	}
+	return map[string]interface{}{"bytes": base64.StdEncoding.EncodeToString(v)}
+}
+
+func isPrintable(v []byte) bool {
+	if !utf8.Valid(v) {
+		return false
+	}
+	for _, r := range string(v) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+Observed usage of the field number.
+ */
+type WireField struct {
+
+	/*
+	Number of records with the field.
+	 */
+	Records int64
+
+	/*
+	Field was seen more than once in a record.
+	 */
+	Repeated bool
+
+	/*
+	Counts of wire types, also separating length-delimited values by convention of DecodeWireMessage: string, message, bytes.
+	 */
+	Types map[string]int64
+}
+
+/**
+Summary of observed top-level fields of sampled frames to help reconstruct the schema.
+ */
+type WireReport struct {
+	Frames int64
+	Fields map[int32]*WireField
+}
+
+/*
+Adds top-level fields of the frame payload to the report.
+ */
+func (r *WireReport) Add(payload []byte) error {
+	if r.Fields == nil {
+		r.Fields = make(map[int32]*WireField)
+	}
+	r.Frames++
+	seen := make(map[int32]bool)
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		kind := "group"
+		switch typ {
+		case protowire.VarintType:
+			kind = "varint"
+		case protowire.Fixed32Type:
+			kind = "fixed32"
+		case protowire.Fixed64Type:
+			kind = "fixed64"
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(payload)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			switch value := decodeWireBytes(v, 0).(type) {
+			case string:
+				kind = "string"
+			case map[string]interface{}:
+				kind = "message"
+				if _, ok := value["bytes"]; ok {
+					kind = "bytes"
+				}
+			}
+		}
+		n = protowire.ConsumeFieldValue(num, typ, payload)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		f, ok := r.Fields[int32(num)]
+		if !ok {
+			f = &WireField{Types: make(map[string]int64)}
+			r.Fields[int32(num)] = f
+		}
+		if seen[int32(num)] {
+			f.Repeated = true
+		} else {
+			seen[int32(num)] = true
+			f.Records++
+		}
+		f.Types[kind]++
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2023 Zander Schwid & Co. LLC.
+ * SPDX-License-Identifier: BUSL-1.1
+ */
+
+package fs
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeWireMessage(t *testing.T) {
+	var nested []byte
+	nested = protowire.AppendTag(nested, 1, protowire.VarintType)
+	nested = protowire.AppendVarint(nested, 150)
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, 7)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte("hello world"))
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, nested)
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{0xff, 0xfe})
+	b = protowire.AppendTag(b, 5, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, 9)
+	b = protowire.AppendTag(b, 5, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, 10)
+	b = protowire.AppendTag(b, 6, protowire.StartGroupType)
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	b = protowire.AppendTag(b, 6, protowire.EndGroupType)
+	b = protowire.AppendTag(b, 7, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, 11)
+	tree, err := DecodeWireMessage(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"1": uint64(7),
+		"2": "hello world",
+		"3": map[string]interface{}{"1": uint64(150)},
+		"4": map[string]interface{}{"bytes": "//4="},
+		"5": []interface{}{uint32(9), uint32(10)},
+		"7": uint64(11),
+	}
+	if !reflect.DeepEqual(tree, want) {
+		t.Fatalf("tree %v, want %v", tree, want)
+	}
+}
+
+func TestDecodeWirePrintableMessage(t *testing.T) {
+	var nested []byte
+	nested = protowire.AppendTag(nested, 2, protowire.BytesType)
+	nested = protowire.AppendBytes(nested, []byte("abc"))
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, nested)
+	tree, err := DecodeWireMessage(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"1": map[string]interface{}{"2": "abc"}}; !reflect.DeepEqual(tree, want) {
+		t.Fatalf("tree %v, want %v", tree, want)
+	}
+}
+
+func TestDecodeWireTruncated(t *testing.T) {
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	b = protowire.AppendVarint(b, 10)
+	if _, err := DecodeWireMessage(append(b, 'x')); err == nil {
+		t.Fatal("truncated bytes accepted")
+	}
+	if _, err := DecodeWireMessage(protowire.AppendTag(nil, 1, protowire.EndGroupType)); err == nil {
+		t.Fatal("unmatched end group accepted")
+	}
+}
+
+func TestWireReport(t *testing.T) {
+	var nested []byte
+	nested = protowire.AppendTag(nested, 1, protowire.VarintType)
+	nested = protowire.AppendVarint(nested, 1)
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte("name"))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, nested)
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{0xff})
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{0xfe})
+	var r WireReport
+	if err := r.Add(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(b[:len(b)-1]); err == nil {
+		t.Fatal("truncated frame accepted")
+	}
+	if r.Frames != 2 {
+		t.Fatalf("%d frames", r.Frames)
+	}
+	if f := r.Fields[1]; f.Records != 2 || f.Types["string"] != 2 {
+		t.Errorf("field 1 %+v", f)
+	}
+	if f := r.Fields[2]; f.Types["message"] != 2 {
+		t.Errorf("field 2 %+v", f)
+	}
+	if f := r.Fields[3]; !f.Repeated || f.Records != 2 || f.Types["bytes"] != 3 {
+		t.Errorf("field 3 %+v", f)
+	}
+}